@@ -4,11 +4,16 @@ import (
 	"log"
 	"os"
 
+	"github.com/HMZElidrissi/eol-checker/internal/cli"
 	"github.com/HMZElidrissi/eol-checker/internal/tui"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
 	model := tui.NewModel()
 	p := tea.NewProgram(model)
 
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"sync"
+
+	"github.com/HMZElidrissi/eol-checker/internal/models"
+)
+
+// CycleFetcher fetches the EOL cycles of a product
+type CycleFetcher interface {
+	GetProductCycles(product string) ([]models.EOLCycle, error)
+}
+
+// Cache remembers the cycles of each product, so that a product is fetched
+// once however many references use it. It is safe for concurrent use:
+// concurrent requests for the same product wait for a single fetch.
+type Cache struct {
+	fetcher CycleFetcher
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	once   sync.Once
+	cycles []models.EOLCycle
+	err    error
+}
+
+// NewCache creates a cache in front of fetcher
+func NewCache(fetcher CycleFetcher) *Cache {
+	return &Cache{
+		fetcher: fetcher,
+		entries: map[string]*cacheEntry{},
+	}
+}
+
+// GetProductCycles returns the cycles of product, fetching them on first use.
+// Errors are cached too, so an unreachable API is reported consistently.
+func (c *Cache) GetProductCycles(product string) ([]models.EOLCycle, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[product]
+	if !ok {
+		entry = &cacheEntry{}
+		c.entries[product] = entry
+	}
+	c.mu.Unlock()
+
+	entry.once.Do(func() {
+		entry.cycles, entry.err = c.fetcher.GetProductCycles(product)
+	})
+	return entry.cycles, entry.err
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/HMZElidrissi/eol-checker/internal/models"
@@ -20,14 +21,35 @@ type Client struct {
 	baseURL    string
 }
 
+// ClientOption configures a Client
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the HTTP client used for API requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithBaseURL sets the API base URL
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
 // NewClient creates a new EOL API client
-func NewClient() *Client {
-	return &Client{
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{
 		httpClient: &http.Client{
 			Timeout: RequestTimeout,
 		},
 		baseURL: EOLAPIBaseURL,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // GetProductCycles fetches EOL cycles for a given product
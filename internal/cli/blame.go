@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/HMZElidrissi/eol-checker/internal/git"
+	"github.com/HMZElidrissi/eol-checker/internal/report"
+)
+
+// addBlame sets the commit that introduced the reference of each checked
+// entry. Files are blamed once each, then the history of each line is
+// followed back to the commit that put the reference there. Files outside a
+// git repository and lines not committed yet get no blame.
+func addBlame(entries []report.Entry, jobs int) {
+	var files []string
+	byFile := map[string][]int{}
+	for i, entry := range entries {
+		if entry.Result == nil || entry.Location == nil || entry.Location.Line == 0 {
+			continue
+		}
+		file := entry.Location.File
+		if byFile[file] == nil {
+			files = append(files, file)
+		}
+		byFile[file] = append(byFile[file], i)
+	}
+
+	roots := make([]string, len(files))
+	blames := make([]map[int]git.BlameLine, len(files))
+	forEach(len(files), jobs, func(i int) {
+		if root, err := git.Toplevel(filepath.Dir(files[i])); err == nil {
+			roots[i] = root
+			blames[i], _ = git.Blame(files[i])
+		}
+	})
+
+	type lookup struct {
+		index int
+		root  string
+		line  git.BlameLine
+	}
+	var lookups []lookup
+	for i, file := range files {
+		for _, index := range byFile[file] {
+			if line, ok := blames[i][entries[index].Location.Line]; ok {
+				lookups = append(lookups, lookup{index: index, root: roots[i], line: line})
+			}
+		}
+	}
+	forEach(len(lookups), jobs, func(i int) {
+		l := lookups[i]
+		commit, err := git.Introduced(l.root, l.line, referenceText(entries[l.index]))
+		if err != nil {
+			commit = l.line.Commit
+		}
+		entries[l.index].Blame = newBlame(commit, entries[l.index].Result.EOLDate)
+	})
+}
+
+// referenceText returns the text of an entry's reference as written in its
+// file, or the checked version for references that are not images
+func referenceText(entry report.Entry) string {
+	switch {
+	case entry.Raw != "":
+		return entry.Raw
+	case entry.Image != "":
+		return entry.Image
+	default:
+		return entry.Result.Version
+	}
+}
+
+// newBlame builds the blame of a reference, counting the days between the
+// EOL date, when there is one, and the commit
+func newBlame(commit git.Commit, eolDate string) *report.Blame {
+	blame := &report.Blame{
+		Commit:  commit.Hash,
+		Author:  commit.Author,
+		Email:   commit.Email,
+		Date:    commit.Time.Format("2006-01-02"),
+		Summary: commit.Summary,
+	}
+	if eol, err := time.Parse("2006-01-02", eolDate); err == nil {
+		days := int(commit.Time.Sub(eol).Hours() / 24)
+		blame.DaysPastEOL = &days
+	}
+	return blame
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+
+	"github.com/HMZElidrissi/eol-checker/internal/api"
+	"github.com/HMZElidrissi/eol-checker/internal/evaluator"
+	"github.com/HMZElidrissi/eol-checker/internal/report"
+	"github.com/HMZElidrissi/eol-checker/internal/version"
+	"github.com/HMZElidrissi/eol-checker/pkg/image"
+)
+
+// runCheck checks every image given on the command line or listed in the
+// given files
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, "Usage: eol check [flags] <image>... | -\n")
+		fs.PrintDefaults()
+	}
+
+	var out outputFlags
+	out.register(fs)
+
+	var files listFlag
+	fs.Var(&files, "file", "read images from a file, or from standard input for -: a list, docker images or crictl images output (repeatable)")
+	fs.Var(&files, "f", "shorthand for --file")
+
+	jobs := fs.Int("jobs", runtime.NumCPU(), "number of images checked concurrently")
+
+	args, err := parseFlags(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if err := out.validate(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if *jobs < 1 {
+		fmt.Fprintln(stderr, "--jobs must be at least 1")
+		return ExitUsage
+	}
+
+	// A lone - reads standard input, like -f -
+	var images []string
+	for _, arg := range args {
+		if arg == "-" {
+			files = append(files, arg)
+		} else {
+			images = append(images, arg)
+		}
+	}
+	for _, file := range files {
+		listed, err := readImageFile(file, os.Stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "failed to read images: %v\n", err)
+			return ExitUsage
+		}
+		images = append(images, listed...)
+	}
+	if len(images) == 0 {
+		if len(files) > 0 {
+			fmt.Fprintln(stderr, "no images to check")
+		} else {
+			fs.Usage()
+		}
+		return ExitUsage
+	}
+
+	// Inventories list the same image many times, check each once
+	seen := map[string]bool{}
+	unique := images[:0]
+	for _, imageName := range images {
+		if !seen[imageName] {
+			seen[imageName] = true
+			unique = append(unique, imageName)
+		}
+	}
+	images = unique
+
+	checker := evaluator.New(api.NewCache(api.NewClient()), version.NewMatcher(), image.NewParser())
+	entries := make([]report.Entry, len(images))
+	forEach(len(images), *jobs, func(i int) {
+		evaluation, err := checker.EvaluateImage(images[i])
+		entries[i] = report.NewEntry(images[i], evaluation, err)
+	})
+
+	return out.write(stdout, stderr, entries)
+}
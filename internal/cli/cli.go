@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const usage = `Usage:
+  eol                       Start the interactive TUI
+  eol check [flags] <image>...
+  eol check [flags] -f <file> | -
+                            Check one or more images, given as arguments or listed
+                            in a file or on standard input, and print the results
+  eol scan [flags] <file or directory>...
+                            Check the images referenced by Dockerfiles, compose files,
+                            Kubernetes manifests, kustomizations, Helm values, ECS task
+                            definitions, Nomad jobs, Quadlet and systemd units, CI
+                            pipelines and Dev Container configurations, the runtimes
+                            pinned in version files and serverless templates, the
+                            database engines and Kubernetes versions of Terraform
+                            configurations, and kind and kubeadm cluster configurations
+`
+
+// Run executes the non-interactive command line and returns the exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return ExitUsage
+	}
+
+	switch args[0] {
+	case "check":
+		return runCheck(args[1:], stdout, stderr)
+	case "scan":
+		return runScan(args[1:], stdout, stderr)
+	case "help", "-h", "--help":
+		fmt.Fprint(stdout, usage)
+		return ExitOK
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usage)
+		return ExitUsage
+	}
+}
+
+// parseFlags parses flags that may be interleaved with positional arguments.
+// Every argument after -- is positional.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		args = rest
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// keyValueFlag collects repeated KEY=VALUE flags. A bare KEY takes its value
+// from the environment and is ignored when the variable is not set.
+type keyValueFlag map[string]string
+
+func (f keyValueFlag) String() string {
+	pairs := make([]string, 0, len(f))
+	for k, v := range f {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f keyValueFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if key == "" {
+		return fmt.Errorf("expected KEY=VALUE, got %q", value)
+	}
+	if !ok {
+		if val, ok = os.LookupEnv(key); !ok {
+			return nil
+		}
+	}
+	f[key] = val
+	return nil
+}
+
+// listFlag collects the values of a repeated flag
+type listFlag []string
+
+func (f *listFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *listFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
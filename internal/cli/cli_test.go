@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		want     []string
+		wantJSON bool
+		wantErr  bool
+	}{
+		{name: "positional only", args: []string{"a", "b"}, want: []string{"a", "b"}},
+		{name: "flags first", args: []string{"--json", "a"}, want: []string{"a"}, wantJSON: true},
+		{name: "interleaved", args: []string{"a", "--json", "b"}, want: []string{"a", "b"}, wantJSON: true},
+		{name: "double dash", args: []string{"a", "--", "-b", "--json"}, want: []string{"a", "-b", "--json"}},
+		{name: "flag before double dash", args: []string{"--json", "--", "-a"}, want: []string{"-a"}, wantJSON: true},
+		{name: "trailing double dash", args: []string{"a", "--"}, want: []string{"a"}},
+		{name: "unknown flag", args: []string{"a", "-b"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			json := fs.Bool("json", false, "")
+			got, err := parseFlags(fs, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFlags(%q) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) || *json != tt.wantJSON {
+				t.Errorf("parseFlags(%q) = %q, json %v, want %q, json %v", tt.args, got, *json, tt.want, tt.wantJSON)
+			}
+		})
+	}
+}
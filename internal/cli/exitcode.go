@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"github.com/HMZElidrissi/eol-checker/internal/models"
+	"github.com/HMZElidrissi/eol-checker/internal/report"
+)
+
+// Exit codes of the non-interactive commands
+const (
+	ExitOK              = 0
+	ExitPolicyViolation = 1
+	ExitUsage           = 2
+	ExitLookupError     = 3
+	ExitUnknown         = 4
+	ExitIncomplete      = 5
+	ExitOutputError     = 6
+)
+
+// policy decides the exit code of a run from its entries
+type policy struct {
+	// failOn is the least severe status that violates the policy; empty
+	// disables policy checks
+	failOn        string
+	ignoreUnknown bool
+}
+
+// exitCode returns the exit code for entries. A policy violation takes
+// precedence over lookup errors, then unknown products, then references that
+// could not be checked because their file could not be read or parsed, the
+// reference is invalid or it has unresolved variables.
+func (p policy) exitCode(entries []report.Entry) int {
+	var violation, lookupError, unknown, incomplete bool
+	for _, entry := range entries {
+		switch {
+		case entry.Error != "" && entry.FetchFailed:
+			lookupError = true
+		case entry.Error != "":
+			incomplete = true
+		case entry.Result.Status == models.StatusUnknown:
+			unknown = true
+		case p.failOn != "" && models.Severity(entry.Result.Status) >= models.Severity(p.failOn):
+			violation = true
+		}
+	}
+
+	switch {
+	case violation:
+		return ExitPolicyViolation
+	case lookupError:
+		return ExitLookupError
+	case unknown && !p.ignoreUnknown:
+		return ExitUnknown
+	case incomplete:
+		return ExitIncomplete
+	default:
+		return ExitOK
+	}
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/HMZElidrissi/eol-checker/internal/models"
+	"github.com/HMZElidrissi/eol-checker/internal/report"
+)
+
+func result(status string) report.Entry {
+	return report.Entry{Image: "nginx:1.20", Result: &models.EOLResult{Status: status}}
+}
+
+func TestExitCode(t *testing.T) {
+	fetchError := report.Entry{Image: "nginx:1.20", Error: "failed to fetch EOL data: timeout", FetchFailed: true}
+	fileError := report.Entry{Location: &report.Location{File: "Dockerfile"}, Error: "line 2: invalid FROM instruction"}
+	unresolved := report.Entry{Image: "node:${X}", Unresolved: []string{"X"}, Error: "unresolved variable X, so the image could not be checked"}
+
+	tests := []struct {
+		name    string
+		policy  policy
+		entries []report.Entry
+		want    int
+	}{
+		{"no entries", policy{}, nil, ExitOK},
+		{"critical without policy", policy{}, []report.Entry{result(models.StatusCritical)}, ExitOK},
+		{"critical fails on critical", policy{failOn: models.StatusCritical}, []report.Entry{result(models.StatusCritical)}, ExitPolicyViolation},
+		{"warning passes critical", policy{failOn: models.StatusCritical}, []report.Entry{result(models.StatusWarning)}, ExitOK},
+		{"critical fails on info", policy{failOn: models.StatusInfo}, []report.Entry{result(models.StatusCritical)}, ExitPolicyViolation},
+		{"ok passes info", policy{failOn: models.StatusInfo}, []report.Entry{result(models.StatusOK)}, ExitOK},
+		{"fetch error", policy{}, []report.Entry{fetchError}, ExitLookupError},
+		{"unknown", policy{}, []report.Entry{result(models.StatusUnknown)}, ExitUnknown},
+		{"ignored unknown", policy{ignoreUnknown: true}, []report.Entry{result(models.StatusUnknown)}, ExitOK},
+		{"file error", policy{}, []report.Entry{fileError}, ExitIncomplete},
+		{"unresolved", policy{}, []report.Entry{unresolved}, ExitIncomplete},
+		{"unresolved with ignored unknown", policy{ignoreUnknown: true}, []report.Entry{unresolved}, ExitIncomplete},
+		{"violation wins", policy{failOn: models.StatusWarning}, []report.Entry{fetchError, unresolved, result(models.StatusUnknown), result(models.StatusWarning)}, ExitPolicyViolation},
+		{"fetch error wins over unknown", policy{}, []report.Entry{result(models.StatusUnknown), fetchError}, ExitLookupError},
+		{"unknown wins over unresolved", policy{}, []report.Entry{unresolved, result(models.StatusUnknown)}, ExitUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.exitCode(tt.entries); got != tt.want {
+				t.Errorf("exitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// readImageFile reads the image list at path, or standard input for "-"
+func readImageFile(path string, stdin io.Reader) ([]string, error) {
+	if path == "-" {
+		return readImageList(stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readImageList(f)
+}
+
+// readImageList reads image references from an inventory. The format is
+// detected from the first line:
+//   - docker images tables, with REPOSITORY and TAG columns
+//   - crictl images tables, with IMAGE and TAG columns
+//   - anything else is a list of references separated by whitespace, such
+//     as docker images --format '{{.Repository}}:{{.Tag}}' or the jsonpath
+//     output of kubectl
+//
+// Blank lines, # comments and images without a tag (<none>) are skipped.
+func readImageList(r io.Reader) ([]string, error) {
+	var images []string
+	table := false
+	first := true
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "#"); i >= 0 && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if first {
+			first = false
+			if len(fields) >= 2 && (fields[0] == "REPOSITORY" || fields[0] == "IMAGE") && fields[1] == "TAG" {
+				table = true
+				continue
+			}
+		}
+
+		if table {
+			if len(fields) < 2 || fields[0] == "<none>" || fields[1] == "<none>" {
+				continue
+			}
+			images = append(images, fields[0]+":"+fields[1])
+			continue
+		}
+		for _, field := range fields {
+			if strings.Contains(field, "<none>") {
+				continue
+			}
+			images = append(images, field)
+		}
+	}
+	return images, sc.Err()
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadImageList(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name: "docker images",
+			input: `REPOSITORY   TAG         IMAGE ID       CREATED        SIZE
+nginx        1.25        a8758716bb6a   2 weeks ago    187MB
+node         18-alpine   37b4077cbd8a   3 weeks ago    127MB
+<none>       <none>      0d9c4e6f1a2b   4 weeks ago    98MB
+redis        <none>      5f2c0b0e1d3a   5 weeks ago    117MB
+`,
+			want: []string{"nginx:1.25", "node:18-alpine"},
+		},
+		{
+			name: "crictl images",
+			input: `IMAGE                                TAG       IMAGE ID        SIZE
+registry.k8s.io/coredns/coredns      v1.10.1   ead0a4a53df89   16.2MB
+docker.io/library/postgres           15        b1d2c3e4f5a6    150MB
+`,
+			want: []string{"registry.k8s.io/coredns/coredns:v1.10.1", "docker.io/library/postgres:15"},
+		},
+		{
+			name:  "whitespace separated",
+			input: "nginx:1.25 redis:7\n\tpython:3.12\n\n",
+			want:  []string{"nginx:1.25", "redis:7", "python:3.12"},
+		},
+		{
+			name: "comments",
+			input: `# production images
+nginx:1.25 # web
+registry.example.com:5000/app#1:2.0
+`,
+			want: []string{"nginx:1.25", "registry.example.com:5000/app#1:2.0"},
+		},
+		{
+			name:  "none in a list",
+			input: "nginx:<none>\n<none>:<none>\nredis:7\n",
+			want:  []string{"redis:7"},
+		},
+		{
+			name:  "empty",
+			input: "\n# nothing\n",
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readImageList(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("readImageList() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/HMZElidrissi/eol-checker/internal/models"
+	"github.com/HMZElidrissi/eol-checker/internal/report"
+)
+
+// outputFlags holds the report and exit code flags shared by commands
+type outputFlags struct {
+	output     string
+	failOn     string
+	reportOpts report.Options
+	policy     policy
+	format     report.Format
+}
+
+// register adds the output flags to fs
+func (o *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.output, "output", string(report.FormatText), "output format: text, json, ndjson, sarif or junit")
+	fs.StringVar(&o.output, "o", string(report.FormatText), "shorthand for --output")
+	fs.BoolVar(&o.reportOpts.WarningsAsFailures, "junit-fail-on-warning", false, "report WARNING results as failures in JUnit output")
+	fs.StringVar(&o.failOn, "fail-on", "", "exit with code 1 when a result is at least this severe: CRITICAL, WARNING or INFO")
+	fs.BoolVar(&o.policy.ignoreUnknown, "ignore-unknown", false, "do not exit with code 4 when a product or version is unknown")
+}
+
+// validate checks the parsed flag values
+func (o *outputFlags) validate() error {
+	format, err := report.ParseFormat(o.output)
+	if err != nil {
+		return err
+	}
+	o.format = format
+
+	if o.failOn != "" {
+		// Failing on OK would fail every run
+		status, err := models.ParseStatus(o.failOn)
+		if err != nil || status == models.StatusOK {
+			return fmt.Errorf("--fail-on: invalid status %q (expected one of %s, %s or %s)",
+				o.failOn, models.StatusCritical, models.StatusWarning, models.StatusInfo)
+		}
+		o.policy.failOn = status
+	}
+	return nil
+}
+
+// write reports entries and returns the exit code of the run
+func (o *outputFlags) write(stdout, stderr io.Writer, entries []report.Entry) int {
+	if err := report.Write(stdout, o.format, entries, o.reportOpts); err != nil {
+		fmt.Fprintf(stderr, "failed to write report: %v\n", err)
+		return ExitOutputError
+	}
+	return o.policy.exitCode(entries)
+}
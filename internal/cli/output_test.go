@@ -0,0 +1,32 @@
+package cli
+
+import "testing"
+
+func TestValidateFailOn(t *testing.T) {
+	tests := []struct {
+		failOn  string
+		want    string
+		wantErr bool
+	}{
+		{failOn: "", want: ""},
+		{failOn: "CRITICAL", want: "CRITICAL"},
+		{failOn: "warning", want: "WARNING"},
+		{failOn: "Info", want: "INFO"},
+		{failOn: "OK", wantErr: true},
+		{failOn: "ok", wantErr: true},
+		{failOn: "UNKNOWN", wantErr: true},
+		{failOn: "severe", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.failOn, func(t *testing.T) {
+			o := outputFlags{output: "text", failOn: tt.failOn}
+			err := o.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate() error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && o.policy.failOn != tt.want {
+				t.Errorf("policy.failOn = %q, want %q", o.policy.failOn, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,314 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/HMZElidrissi/eol-checker/internal/api"
+	"github.com/HMZElidrissi/eol-checker/internal/evaluator"
+	"github.com/HMZElidrissi/eol-checker/internal/report"
+	"github.com/HMZElidrissi/eol-checker/internal/scanner"
+	"github.com/HMZElidrissi/eol-checker/internal/version"
+	"github.com/HMZElidrissi/eol-checker/pkg/image"
+)
+
+// runScan checks the images referenced by the given files and by the files
+// found in the given directories
+func runScan(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("scan", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, "Usage: eol scan [flags] <file or directory>...\n")
+		fs.PrintDefaults()
+	}
+
+	var out outputFlags
+	out.register(fs)
+
+	buildArgs := keyValueFlag{}
+	fs.Var(buildArgs, "build-arg", "set a Dockerfile build-time variable as KEY=VALUE, or KEY to take it from the environment (repeatable)")
+
+	var valuesFiles listFlag
+	fs.Var(&valuesFiles, "values", "layer a Helm values file over a chart's values.yaml, like helm -f, as [CHART_DIR=]FILE; the chart defaults to the one FILE is in, or the only chart scanned (repeatable)")
+
+	var walkOpts scanner.WalkOptions
+	fs.Var((*listFlag)(&walkOpts.Include), "include", "only scan the files of directories matching this glob, e.g. '**/*.tf' (repeatable)")
+	fs.Var((*listFlag)(&walkOpts.Exclude), "exclude", "skip the files and directories matching this glob, e.g. 'vendor' (repeatable)")
+
+	jobs := fs.Int("jobs", runtime.NumCPU(), "number of files scanned and references checked concurrently")
+
+	blame := fs.Bool("blame", false, "show the git commit, author and date that introduced each reference, and how long after its EOL")
+
+	since := fs.String("since", "", "only report the references resolved from files changed since HEAD branched off this git revision, when they are new or worse than at the branch point, e.g. origin/main")
+
+	paths, err := parseFlags(fs, args)
+	if err != nil {
+		if err == flag.ErrHelp {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+	if len(paths) == 0 {
+		fs.Usage()
+		return ExitUsage
+	}
+	if err := out.validate(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	if *jobs < 1 {
+		fmt.Fprintln(stderr, "--jobs must be at least 1")
+		return ExitUsage
+	}
+
+	opts := scanner.Options{BuildArgs: buildArgs}
+	targets := scanTargets(scanner.NewSet(opts), paths, walkOpts)
+	if opts.ValuesFiles, err = chartValuesFiles(valuesFiles, targets); err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitUsage
+	}
+	scanners := scanner.NewSet(opts)
+
+	var bases baselines
+	if *since != "" {
+		if bases, err = loadBaselines(*since, paths); err != nil {
+			fmt.Fprintln(stderr, err)
+			return ExitUsage
+		}
+		defer bases.close()
+	}
+
+	scanFiles(scanners, targets, *jobs)
+	if bases != nil {
+		// Every file is scanned, since a reference may be resolved from
+		// changed files that are not scanned themselves, such as a .env
+		targets = bases.changedReferences(targets)
+	}
+
+	// The cache fetches each product once, for the base revision too
+	checker := evaluator.New(api.NewCache(api.NewClient()), version.NewMatcher(), image.NewParser())
+	entries := checkTargets(checker, targets, *jobs)
+	if bases != nil {
+		baseOpts := opts
+		baseOpts.ValuesFiles = bases.valuesFiles(opts.ValuesFiles)
+		baseTargets := bases.targets(targets)
+		scanFiles(scanner.NewSet(baseOpts), baseTargets, *jobs)
+		entries = bases.newFindings(entries, checkTargets(checker, baseTargets, *jobs))
+	}
+	if *blame {
+		addBlame(entries, *jobs)
+	}
+
+	return out.write(stdout, stderr, entries)
+}
+
+// scanFiles scans the targets that could be walked
+func scanFiles(scanners *scanner.Set, targets []scanTarget, jobs int) {
+	forEach(len(targets), jobs, func(i int) {
+		if targets[i].err == nil {
+			targets[i].refs, targets[i].err = scanners.ScanFile(targets[i].path)
+		}
+	})
+}
+
+// checkTargets checks the references the scanned targets contain.
+// Identical references are checked once.
+func checkTargets(checker *evaluator.Evaluator, targets []scanTarget, jobs int) []report.Entry {
+
+	checks := map[string]*referenceCheck{}
+	var pending []*referenceCheck
+	for _, target := range targets {
+		for _, ref := range target.refs {
+			key := referenceKey(ref)
+			if len(ref.Unresolved) == 0 && checks[key] == nil {
+				checks[key] = &referenceCheck{ref: ref}
+				pending = append(pending, checks[key])
+			}
+		}
+	}
+	forEach(len(pending), jobs, func(i int) {
+		pending[i].evaluation, pending[i].err = evaluateReference(checker, pending[i].ref)
+	})
+
+	var entries []report.Entry
+	for _, target := range targets {
+		if target.err != nil {
+			entries = append(entries, report.Entry{
+				Location: &report.Location{File: target.path},
+				Error:    target.err.Error(),
+			})
+			continue
+		}
+		for _, ref := range target.refs {
+			if len(ref.Unresolved) > 0 {
+				entries = append(entries, referenceEntry(ref, nil, unresolvedError(ref)))
+				continue
+			}
+			check := checks[referenceKey(ref)]
+			entries = append(entries, referenceEntry(ref, check.evaluation, check.err))
+		}
+	}
+	return entries
+}
+
+// scanTarget is a file to scan, or a path that could not be walked
+type scanTarget struct {
+	path string
+	refs []scanner.Reference
+	err  error
+}
+
+// scanTargets expands directories into the files they contain that a
+// scanner handles. Files named on the command line are kept as they are, so
+// that unsupported ones are reported.
+func scanTargets(scanners *scanner.Set, paths []string, opts scanner.WalkOptions) []scanTarget {
+	var targets []scanTarget
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			targets = append(targets, scanTarget{path: path})
+			continue
+		}
+		files, err := scanners.Walk(path, opts)
+		if err != nil {
+			targets = append(targets, scanTarget{path: path, err: err})
+			continue
+		}
+		for _, file := range files {
+			targets = append(targets, scanTarget{path: file})
+		}
+	}
+	return targets
+}
+
+// chartValuesFiles finds the chart of each --values flag, given as
+// [CHART_DIR=]FILE. Without a directory, a file applies to the chart it is
+// in, or else to the only chart among the targets, since helm -f applies to
+// a single chart.
+func chartValuesFiles(flags []string, targets []scanTarget) ([]scanner.ValuesFile, error) {
+	var charts []string
+	seen := map[string]bool{}
+	for _, target := range targets {
+		if dir := filepath.Dir(target.path); scanner.IsChartValues(target.path) && !seen[dir] {
+			seen[dir] = true
+			charts = append(charts, dir)
+		}
+	}
+
+	var files []scanner.ValuesFile
+	for _, flag := range flags {
+		chart, file, ok := strings.Cut(flag, "=")
+		if !ok {
+			file = flag
+			chart = enclosingChart(file)
+		}
+		if chart == "" {
+			switch len(charts) {
+			case 0:
+				continue
+			case 1:
+				chart = charts[0]
+			default:
+				return nil, fmt.Errorf("--values %s: %d charts are scanned, give the chart as CHART_DIR=%s", file, len(charts), file)
+			}
+		}
+		if _, err := os.Stat(file); err != nil {
+			return nil, fmt.Errorf("--values: %w", err)
+		}
+		files = append(files, scanner.ValuesFile{Chart: chart, Path: file})
+	}
+	return files, nil
+}
+
+// enclosingChart returns the closest directory holding path that has a
+// Chart.yaml, or "" when there is none
+func enclosingChart(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return ""
+	}
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		for _, name := range []string{"Chart.yaml", "Chart.yml"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return dir
+			}
+		}
+		if dir == filepath.Dir(dir) {
+			return ""
+		}
+	}
+}
+
+// referenceCheck is the result of checking a reference
+type referenceCheck struct {
+	ref        scanner.Reference
+	evaluation *evaluator.Evaluation
+	err        error
+}
+
+// referenceKey identifies the references that check the same thing
+func referenceKey(ref scanner.Reference) string {
+	if ref.Image != "" {
+		return "image\x00" + ref.Image
+	}
+	return ref.Product + "\x00" + ref.Version + "\x00" + ref.Subject
+}
+
+// forEach calls fn with every index below n, running up to jobs calls at
+// once
+func forEach(n, jobs int, fn func(i int)) {
+	var wg sync.WaitGroup
+	indexes := make(chan int)
+	for w := 0; w < jobs && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// evaluateReference checks an image reference or a product reference
+func evaluateReference(checker *evaluator.Evaluator, ref scanner.Reference) (*evaluator.Evaluation, error) {
+	if ref.Image == "" {
+		return checker.EvaluateProduct(ref.Subject, ref.Product, ref.Version)
+	}
+	return checker.EvaluateImage(ref.Image)
+}
+
+// referenceEntry builds a report entry for a reference found by a scanner
+func referenceEntry(ref scanner.Reference, evaluation *evaluator.Evaluation, err error) report.Entry {
+	entry := report.NewEntry(ref.Image, evaluation, err)
+	entry.Subject = ref.Subject
+	entry.Raw = ref.Raw
+	entry.Unresolved = ref.Unresolved
+	entry.Location = &report.Location{File: ref.File, Line: ref.Line}
+	entry.Context = ref.Context
+	return entry
+}
+
+// unresolvedError explains why a reference with unset variables was not checked
+func unresolvedError(ref scanner.Reference) error {
+	what := "image"
+	if ref.Image == "" {
+		what = "version"
+	}
+	names := strings.Join(ref.Unresolved, ", ")
+	if len(ref.Unresolved) == 1 {
+		return fmt.Errorf("unresolved variable %s, so the %s could not be checked", names, what)
+	}
+	return fmt.Errorf("unresolved variables %s, so the %s could not be checked", names, what)
+}
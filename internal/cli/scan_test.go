@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/HMZElidrissi/eol-checker/internal/scanner"
+)
+
+func TestChartValuesFiles(t *testing.T) {
+	root := t.TempDir()
+	api := filepath.Join(root, "charts", "api")
+	web := filepath.Join(root, "charts", "web")
+	for _, path := range []string{
+		filepath.Join(api, "Chart.yaml"),
+		filepath.Join(api, "values.yaml"),
+		filepath.Join(api, "values-prod.yaml"),
+		filepath.Join(web, "values.yaml"),
+		filepath.Join(root, "prod.yaml"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prod := filepath.Join(root, "prod.yaml")
+	both := []scanTarget{{path: filepath.Join(api, "values.yaml")}, {path: filepath.Join(web, "values.yaml")}}
+
+	tests := []struct {
+		name    string
+		flags   []string
+		targets []scanTarget
+		want    []scanner.ValuesFile
+		wantErr bool
+	}{
+		{
+			name:    "file in a chart",
+			flags:   []string{filepath.Join(api, "values-prod.yaml")},
+			targets: both,
+			want:    []scanner.ValuesFile{{Chart: api, Path: filepath.Join(api, "values-prod.yaml")}},
+		},
+		{
+			name:    "only chart scanned",
+			flags:   []string{prod},
+			targets: both[1:],
+			want:    []scanner.ValuesFile{{Chart: web, Path: prod}},
+		},
+		{
+			name:    "explicit chart",
+			flags:   []string{web + "=" + prod},
+			targets: both,
+			want:    []scanner.ValuesFile{{Chart: web, Path: prod}},
+		},
+		{
+			name:    "several charts",
+			flags:   []string{prod},
+			targets: both,
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			flags:   []string{web + "=" + filepath.Join(root, "missing.yaml")},
+			targets: both,
+			wantErr: true,
+		},
+		{
+			name:    "no chart scanned",
+			flags:   []string{prod},
+			targets: []scanTarget{{path: filepath.Join(root, "Dockerfile")}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := chartValuesFiles(tt.flags, tt.targets)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("chartValuesFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chartValuesFiles() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
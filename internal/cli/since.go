@@ -0,0 +1,409 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/HMZElidrissi/eol-checker/internal/git"
+	"github.com/HMZElidrissi/eol-checker/internal/models"
+	"github.com/HMZElidrissi/eol-checker/internal/report"
+	"github.com/HMZElidrissi/eol-checker/internal/scanner"
+)
+
+// baseline is the tree of a git revision that a scan is compared with
+type baseline struct {
+	// root is the working tree and dir the exported revision
+	root string
+	dir  string
+	cwd  string
+	// changes are keyed by path relative to root, and renamed maps the
+	// base path of renamed files to their current path
+	changes map[string]*git.Change
+	renamed map[string]string
+}
+
+// baselines are the base revisions of the repositories that hold the
+// scanned paths
+type baselines []*baseline
+
+// loadBaselines loads the baseline of every repository that holds one of
+// paths, once per repository
+func loadBaselines(ref string, paths []string) (baselines, error) {
+	var bs baselines
+	for _, path := range paths {
+		dir := path
+		if info, err := os.Stat(path); err != nil || !info.IsDir() {
+			dir = filepath.Dir(path)
+		}
+		root, err := git.Toplevel(dir)
+		if err != nil {
+			bs.close()
+			return nil, fmt.Errorf("--since: %w", err)
+		}
+		if slices.ContainsFunc(bs, func(b *baseline) bool { return b.root == resolvePath(root) }) {
+			continue
+		}
+		b, err := loadBaseline(ref, root)
+		if err != nil {
+			bs.close()
+			return nil, err
+		}
+		bs = append(bs, b)
+	}
+	return bs, nil
+}
+
+// loadBaseline finds the files changed since the merge base of ref and HEAD
+// in the repository at root, and exports that revision to a temporary
+// directory
+func loadBaseline(ref, root string) (*baseline, error) {
+	if err := git.Verify(root, ref); err != nil {
+		return nil, fmt.Errorf("--since: %w", err)
+	}
+	// Compare with the branch point, not with what ref became since
+	base, err := git.MergeBase(root, ref)
+	if err != nil {
+		return nil, fmt.Errorf("--since: %w", err)
+	}
+	changes, err := git.Changes(root, base)
+	if err != nil {
+		return nil, fmt.Errorf("--since: %w", err)
+	}
+
+	b := &baseline{
+		root:    resolvePath(root),
+		cwd:     ".",
+		changes: changes,
+		renamed: map[string]string{},
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		b.cwd = resolvePath(cwd)
+	}
+	for path, change := range changes {
+		if change.OldPath != "" && change.OldPath != path {
+			b.renamed[change.OldPath] = path
+		}
+	}
+
+	tmp, err := os.MkdirTemp("", "eol-since-")
+	if err != nil {
+		return nil, err
+	}
+	b.dir = resolvePath(tmp)
+	if err := git.Export(root, base, b.dir); err != nil {
+		os.RemoveAll(tmp)
+		return nil, fmt.Errorf("--since: %w", err)
+	}
+	return b, nil
+}
+
+// close removes the exported revisions
+func (bs baselines) close() {
+	for _, b := range bs {
+		os.RemoveAll(b.dir)
+	}
+}
+
+// resolvePath makes path absolute and resolves symlinks, so that paths can
+// be compared with the ones git reports
+func resolvePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	return path
+}
+
+// relative returns path relative to tree, with slashes, when it is inside
+// tree. Relative paths are taken from the working directory.
+func (b *baseline) relative(path, tree string) (string, bool) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(b.cwd, path)
+	}
+	rel, err := filepath.Rel(tree, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// of returns the baseline whose working tree or exported revision holds
+// path, the innermost one for nested repositories, or nil
+func (bs baselines) of(path string) *baseline {
+	path = resolvePath(path)
+	var found *baseline
+	depth := -1
+	for _, b := range bs {
+		for _, tree := range []string{b.root, b.dir} {
+			if _, ok := b.relative(path, tree); ok && len(tree) > depth {
+				found, depth = b, len(tree)
+			}
+		}
+	}
+	return found
+}
+
+// changed reports whether the file at path, in the working tree or in the
+// exported revision, changed since the base revision
+func (bs baselines) changed(path string) bool {
+	b := bs.of(path)
+	if b == nil {
+		return false
+	}
+	path = resolvePath(path)
+	// The exported revision is checked first, in case the temporary
+	// directory is inside the repository
+	if rel, ok := b.relative(path, b.dir); ok {
+		if current, ok := b.renamed[rel]; ok {
+			rel = current
+		}
+		return b.changes[rel] != nil
+	}
+	rel, _ := b.relative(path, b.root)
+	return b.changes[rel] != nil
+}
+
+// basePath returns where a file of the working tree was in the base
+// revision, following renames
+func (bs baselines) basePath(path string) (string, bool) {
+	path = resolvePath(path)
+	b := bs.of(path)
+	if b == nil {
+		return "", false
+	}
+	rel, ok := b.relative(path, b.root)
+	if !ok {
+		return "", false
+	}
+	if change := b.changes[rel]; change != nil && change.OldPath != "" {
+		rel = change.OldPath
+	}
+	return filepath.Join(b.dir, filepath.FromSlash(rel)), true
+}
+
+// changedReferences keeps the references that were resolved from a file
+// changed since the base revision: their own, or another one such as the
+// base of a kustomization or a .env file. Errors are kept for changed files
+// and for paths that are not files, such as directories that could not be
+// walked.
+func (bs baselines) changedReferences(targets []scanTarget) []scanTarget {
+	var changed []scanTarget
+	for _, target := range targets {
+		if target.err != nil {
+			if !scanner.FileExists(target.path) || bs.changed(target.path) {
+				changed = append(changed, target)
+			}
+			continue
+		}
+		var refs []scanner.Reference
+		for _, ref := range target.refs {
+			if bs.changed(ref.File) || slices.ContainsFunc(ref.Sources, bs.changed) {
+				refs = append(refs, ref)
+			}
+		}
+		if len(refs) > 0 {
+			target.refs = refs
+			changed = append(changed, target)
+		}
+	}
+	return changed
+}
+
+// targets returns the base revision of the targets that existed there
+func (bs baselines) targets(targets []scanTarget) []scanTarget {
+	var base []scanTarget
+	for _, target := range targets {
+		if path, ok := bs.basePath(target.path); ok && scanner.FileExists(path) {
+			base = append(base, scanTarget{path: path})
+		}
+	}
+	return base
+}
+
+// valuesFiles maps Helm values files to the base revision: the charts of the
+// working tree are the charts of the exported tree, and the values files
+// that existed are read as they were
+func (bs baselines) valuesFiles(files []scanner.ValuesFile) []scanner.ValuesFile {
+	base := make([]scanner.ValuesFile, len(files))
+	for i, file := range files {
+		base[i] = file
+		if chart, ok := bs.basePath(file.Chart); ok {
+			base[i].Chart = chart
+		}
+		if path, ok := bs.basePath(file.Path); ok && scanner.FileExists(path) {
+			base[i].Path = path
+		}
+	}
+	return base
+}
+
+// finding identifies an entry across revisions: the file it is in, what it
+// checks, and the slot it fills, such as a service or stage, with the product
+type finding struct {
+	file     string
+	identity string
+	slot     string
+}
+
+// currentFinding describes an entry of the working tree
+func (b *baseline) currentFinding(entry report.Entry) finding {
+	return b.finding(entry, b.root, func(rel string) string { return rel })
+}
+
+// baseFinding describes an entry of the base revision, under the current
+// path of renamed files
+func (b *baseline) baseFinding(entry report.Entry) finding {
+	return b.finding(entry, b.dir, func(rel string) string {
+		if path, ok := b.renamed[rel]; ok {
+			return path
+		}
+		return rel
+	})
+}
+
+func (b *baseline) finding(entry report.Entry, tree string, rename func(string) string) finding {
+	// Paths, in the location and in the context, are made relative to the
+	// tree so that both revisions compare equal
+	normalize := func(path string) string {
+		if rel, ok := b.relative(path, tree); ok {
+			if _, err := os.Stat(filepath.Join(tree, filepath.FromSlash(rel))); err == nil {
+				return rename(rel)
+			}
+		}
+		return path
+	}
+
+	var f finding
+	if entry.Location != nil {
+		f.file = normalize(entry.Location.File)
+	}
+	switch {
+	case entry.Error != "":
+		f.identity = "error\x00" + entry.Error
+	case entry.Image != "":
+		f.identity = entry.Image
+	default:
+		f.identity = entry.Subject
+	}
+
+	product := ""
+	if entry.Result != nil {
+		product = entry.Result.Product
+	}
+	slot := []string{product}
+	for key, value := range entry.Context {
+		slot = append(slot, key+"="+normalize(value))
+	}
+	sort.Strings(slot[1:])
+	f.slot = strings.Join(slot, "\x00")
+	return f
+}
+
+// severity ranks an entry, errors and unknown results lowest
+func severity(entry report.Entry) int {
+	if entry.Result == nil {
+		return -1
+	}
+	return models.Severity(entry.Result.Status)
+}
+
+// newFindings returns the entries of the working tree that are not in the
+// base revision of their repository, or that are more severe than the entry
+// they replace
+func (bs baselines) newFindings(entries, baseEntries []report.Entry) []report.Entry {
+	of := func(entry report.Entry) *baseline {
+		if entry.Location == nil {
+			return nil
+		}
+		return bs.of(entry.Location.File)
+	}
+
+	reported := make([]bool, len(entries))
+	for i, entry := range entries {
+		reported[i] = of(entry) == nil
+	}
+	for _, b := range bs {
+		var current, base []report.Entry
+		var index []int
+		for i, entry := range entries {
+			if of(entry) == b {
+				current = append(current, entry)
+				index = append(index, i)
+			}
+		}
+		for _, entry := range baseEntries {
+			if of(entry) == b {
+				base = append(base, entry)
+			}
+		}
+		for j, isNew := range b.newFindings(current, base) {
+			reported[index[j]] = isNew
+		}
+	}
+
+	var found []report.Entry
+	for i, entry := range entries {
+		if reported[i] {
+			found = append(found, entry)
+		}
+	}
+	return found
+}
+
+// newFindings tells which entries of the working tree are not in the base
+// revision, or are more severe than the entry they replace. Entries on
+// unchanged lines are matched with the base revision first, so that a new
+// copy of an existing reference is reported where it was added.
+func (b *baseline) newFindings(entries, baseEntries []report.Entry) []bool {
+	existing := map[finding]int{}
+	slots := map[string]int{}
+	for _, entry := range baseEntries {
+		f := b.baseFinding(entry)
+		existing[finding{file: f.file, identity: f.identity}]++
+		slot := f.file + "\x00" + f.slot
+		if s, ok := slots[slot]; !ok || severity(entry) > s {
+			slots[slot] = severity(entry)
+		}
+	}
+
+	findings := make([]finding, len(entries))
+	isNew := make([]bool, len(entries))
+	onChangedLine := func(i int) bool {
+		change := b.changes[findings[i].file]
+		line := 0
+		if entries[i].Location != nil {
+			line = entries[i].Location.Line
+		}
+		return change != nil && change.Changed(line)
+	}
+	for i, entry := range entries {
+		findings[i] = b.currentFinding(entry)
+	}
+	for _, changedLines := range []bool{false, true} {
+		for i := range entries {
+			if onChangedLine(i) != changedLines {
+				continue
+			}
+			key := finding{file: findings[i].file, identity: findings[i].identity}
+			if existing[key] > 0 {
+				existing[key]--
+			} else {
+				isNew[i] = true
+			}
+		}
+	}
+
+	for i, entry := range entries {
+		// A changed reference is only reported when it got worse
+		if s, ok := slots[findings[i].file+"\x00"+findings[i].slot]; ok && isNew[i] && entry.Error == "" && severity(entry) <= s {
+			isNew[i] = false
+		}
+	}
+	return isNew
+}
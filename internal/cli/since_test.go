@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/HMZElidrissi/eol-checker/internal/scanner"
+)
+
+// repository creates a git repository with files committed on the base
+// branch, and checks out a work branch from it
+func repository(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	gitCommand(t, dir, "init", "-q")
+	gitCommand(t, dir, "config", "user.name", "Test")
+	gitCommand(t, dir, "config", "user.email", "test@example.com")
+	gitCommand(t, dir, "config", "commit.gpgsign", "false")
+	writeFiles(t, dir, files)
+	gitCommand(t, dir, "add", "-A")
+	gitCommand(t, dir, "commit", "-q", "-m", "base")
+	gitCommand(t, dir, "branch", "base")
+	gitCommand(t, dir, "checkout", "-q", "-b", "work")
+	return dir
+}
+
+func gitCommand(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// scannedReferences describes the references of scanned targets, sorted
+func scannedReferences(targets []scanTarget) []string {
+	var refs []string
+	for _, target := range targets {
+		for _, ref := range target.refs {
+			if ref.Image != "" {
+				refs = append(refs, ref.Image)
+			} else {
+				refs = append(refs, ref.Product+" "+ref.Version)
+			}
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+func TestChangedReferences(t *testing.T) {
+	infra := repository(t, map[string]string{
+		"k8s/base/kustomization.yaml":    "resources:\n  - deploy.yaml\n",
+		"k8s/base/deploy.yaml":           "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  template:\n    spec:\n      containers:\n        - name: web\n          image: nginx:1.27\n",
+		"k8s/overlay/kustomization.yaml": "resources:\n  - ../base\n",
+		"compose.yaml":                   "services:\n  cache:\n    image: redis:${REDIS}\n  db:\n    image: postgres:16\n",
+		".env":                           "REDIS=7.2\n",
+		"db/main.tf":                     "resource \"aws_db_instance\" \"db\" {\n  engine         = \"postgres\"\n  engine_version = var.pg\n}\n",
+		"db/variables.tf":                "variable \"pg\" {\n  default = \"16\"\n}\n",
+		"Dockerfile":                     "FROM python:3.12\n",
+	})
+	app := repository(t, map[string]string{
+		"Dockerfile": "FROM node:22\n",
+		"go.mod":     "module example.com/app\n\ngo 1.24\n",
+	})
+
+	// None of the changed files holds an image itself, except the
+	// Dockerfile of the second repository
+	writeFiles(t, infra, map[string]string{
+		"k8s/base/deploy.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  template:\n    spec:\n      containers:\n        - name: web\n          image: nginx:1.20\n",
+		".env":                 "REDIS=5.0\n",
+		"db/terraform.tfvars":  "pg = \"11\"\n",
+	})
+	writeFiles(t, app, map[string]string{"Dockerfile": "FROM node:16\n"})
+
+	paths := []string{infra, filepath.Join(app, "Dockerfile")}
+	bases, err := loadBaselines("base", paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bases.close()
+	if len(bases) != 2 {
+		t.Fatalf("loaded %d baselines, want one per repository", len(bases))
+	}
+
+	scanners := scanner.NewSet(scanner.Options{})
+	targets := scanTargets(scanners, paths, scanner.WalkOptions{})
+	scanFiles(scanners, targets, 1)
+	changed := bases.changedReferences(targets)
+	want := []string{"nginx:1.20", "node:16", "postgresql 11", "redis:5.0"}
+	if got := scannedReferences(changed); !reflect.DeepEqual(got, want) {
+		t.Errorf("changed references = %q, want %q", got, want)
+	}
+
+	baseTargets := bases.targets(changed)
+	scanFiles(scanners, baseTargets, 1)
+	want = []string{"nginx:1.27", "node:22", "postgres:16", "postgresql 16", "redis:7.2"}
+	if got := scannedReferences(baseTargets); !reflect.DeepEqual(got, want) {
+		t.Errorf("base references = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,299 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HMZElidrissi/eol-checker/internal/api"
+	"github.com/HMZElidrissi/eol-checker/internal/models"
+	"github.com/HMZElidrissi/eol-checker/internal/version"
+	"github.com/HMZElidrissi/eol-checker/pkg/image"
+)
+
+// VersionMatcher picks the EOL cycle that matches a version
+type VersionMatcher interface {
+	FindBestMatch(version string, cycles []models.EOLCycle) *models.EOLCycle
+}
+
+// ImageParser splits an image reference into its components
+type ImageParser interface {
+	Parse(imageName string) (*image.ImageInfo, error)
+}
+
+// Thresholds holds the number of days before EOL or end of support at which
+// a result is reported as WARNING or INFO
+type Thresholds struct {
+	WarningDays int `json:"warningDays"`
+	InfoDays    int `json:"infoDays"`
+}
+
+// DefaultThresholds returns the thresholds used when none are configured
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		WarningDays: 30,
+		InfoDays:    90,
+	}
+}
+
+// Evaluator determines the EOL status of container images
+type Evaluator struct {
+	fetcher    api.CycleFetcher
+	matcher    VersionMatcher
+	parser     ImageParser
+	now        func() time.Time
+	thresholds Thresholds
+}
+
+// Option configures an Evaluator
+type Option func(*Evaluator)
+
+// WithClock sets the function used to get the current time
+func WithClock(now func() time.Time) Option {
+	return func(e *Evaluator) {
+		e.now = now
+	}
+}
+
+// WithThresholds sets the WARNING and INFO thresholds
+func WithThresholds(thresholds Thresholds) Option {
+	return func(e *Evaluator) {
+		e.thresholds = thresholds
+	}
+}
+
+// New creates an evaluator from its dependencies
+func New(fetcher api.CycleFetcher, matcher VersionMatcher, parser ImageParser, opts ...Option) *Evaluator {
+	e := &Evaluator{
+		fetcher:    fetcher,
+		matcher:    matcher,
+		parser:     parser,
+		now:        time.Now,
+		thresholds: DefaultThresholds(),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// NewDefault creates an evaluator backed by the endoflife.date API
+func NewDefault() *Evaluator {
+	return New(api.NewClient(), version.NewMatcher(), image.NewParser())
+}
+
+// FetchError reports that the EOL data of a product could not be fetched,
+// for instance because the API is unreachable
+type FetchError struct {
+	Product string
+	Err     error
+}
+
+func (e *FetchError) Error() string {
+	return fmt.Sprintf("failed to fetch EOL data: %v", e.Err)
+}
+
+func (e *FetchError) Unwrap() error {
+	return e.Err
+}
+
+// IsFetchError reports whether err comes from fetching EOL data, as opposed
+// to an invalid input such as an image that cannot be parsed
+func IsFetchError(err error) bool {
+	var fetchErr *FetchError
+	return errors.As(err, &fetchErr)
+}
+
+// Evaluation is an EOL result together with the data it was derived from
+type Evaluation struct {
+	Image     string           `json:"image,omitempty"`
+	ImageInfo *image.ImageInfo `json:"imageInfo,omitempty"`
+	Cycle     *models.EOLCycle `json:"cycle,omitempty"`
+	Result    models.EOLResult `json:"result"`
+}
+
+// Evaluate checks the EOL status of an image
+func (e *Evaluator) Evaluate(imageName string) (models.EOLResult, error) {
+	evaluation, err := e.EvaluateImage(imageName)
+	if err != nil {
+		return models.EOLResult{}, err
+	}
+	return evaluation.Result, nil
+}
+
+// EvaluateImage checks the EOL status of an image and also returns the parsed
+// image and the matched cycle
+func (e *Evaluator) EvaluateImage(imageName string) (*Evaluation, error) {
+	// Parse image name
+	imageInfo, err := e.parser.Parse(imageName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse image: %w", err)
+	}
+
+	evaluation, err := e.EvaluateProduct("image "+imageName, imageInfo.Product, imageInfo.Version)
+	if err != nil {
+		return nil, err
+	}
+	evaluation.Image = imageName
+	evaluation.ImageInfo = imageInfo
+	return evaluation, nil
+}
+
+// EvaluateProduct checks the EOL status of a product version that does not
+// come from an image, such as a runner OS or a pinned runtime. subject names
+// what uses the version, e.g. "runner ubuntu-20.04", and starts the
+// description of the result.
+func (e *Evaluator) EvaluateProduct(subject, product, version string) (*Evaluation, error) {
+	evaluation := &Evaluation{}
+
+	// Fetch EOL data
+	cycles, err := e.fetcher.GetProductCycles(product)
+	if err != nil {
+		return nil, &FetchError{Product: product, Err: err}
+	}
+
+	if cycles == nil {
+		evaluation.Result = models.EOLResult{
+			Product:     product,
+			Version:     version,
+			Status:      models.StatusUnknown,
+			Description: fmt.Sprintf("Product '%s' not found in EOL database", product),
+		}
+		return evaluation, nil
+	}
+
+	overallLatest := LatestVersion(cycles)
+
+	// Find matching cycle
+	cycleInfo := e.matcher.FindBestMatch(version, cycles)
+	if cycleInfo == nil {
+		evaluation.Result = models.EOLResult{
+			Product:     product,
+			Version:     version,
+			Status:      models.StatusUnknown,
+			Description: fmt.Sprintf("Version '%s' not found for product '%s'", version, product),
+			Latest:      overallLatest,
+		}
+		return evaluation, nil
+	}
+
+	evaluation.Cycle = cycleInfo
+	evaluation.Result = e.buildResult(subject, product, cycleInfo, overallLatest)
+	return evaluation, nil
+}
+
+// LatestVersion returns the latest release of the most recently released cycle
+func LatestVersion(cycles []models.EOLCycle) string {
+	var latestCycle *models.EOLCycle
+	for i := range cycles {
+		if latestCycle == nil {
+			latestCycle = &cycles[i]
+			continue
+		}
+
+		// Compare release dates to find the most recent
+		if cycles[i].ReleaseDate > latestCycle.ReleaseDate {
+			latestCycle = &cycles[i]
+		}
+	}
+
+	if latestCycle == nil {
+		return ""
+	}
+	return latestCycle.Latest
+}
+
+// buildResult builds the final EOL result with status analysis
+func (e *Evaluator) buildResult(subject, product string, cycleInfo *models.EOLCycle, overallLatest string) models.EOLResult {
+	result := models.EOLResult{
+		Product: product,
+		Version: string(cycleInfo.Cycle),
+		Latest:  overallLatest,
+	}
+
+	if cycleInfo.Link != nil {
+		result.Link = *cycleInfo.Link
+	}
+
+	// Parse EOL date
+	eolDateStr, hasEOL := cycleInfo.EOL.(string)
+	var eolDate time.Time
+	var eolDateErr error
+	if hasEOL {
+		result.EOLDate = eolDateStr
+		eolDate, eolDateErr = time.Parse("2006-01-02", eolDateStr)
+	}
+
+	// Parse support end date
+	supportEndStr, hasSupport := cycleInfo.Support.(string)
+	var supportEndDate time.Time
+	var supportEndDateErr error
+	if hasSupport {
+		result.SupportEndDate = supportEndStr
+		supportEndDate, supportEndDateErr = time.Parse("2006-01-02", supportEndStr)
+	}
+
+	// Parse discontinued
+	discontinued := false
+	now := e.now()
+	if d, ok := cycleInfo.Discontinued.(string); ok {
+		if t, err := time.Parse("2006-01-02", d); err == nil && t.Before(now) {
+			discontinued = true
+		}
+	} else if d, ok := cycleInfo.Discontinued.(bool); ok {
+		discontinued = d
+	}
+
+	// Calculate days remaining
+	result.DaysRemaining = -1
+	if hasEOL && eolDateErr == nil {
+		result.DaysRemaining = int(eolDate.Sub(now).Hours() / 24)
+	}
+
+	daysToSupportEnd := -1
+	if hasSupport && supportEndDateErr == nil {
+		daysToSupportEnd = int(supportEndDate.Sub(now).Hours() / 24)
+	}
+
+	// Determine status and messages
+	warningDays := e.thresholds.WarningDays
+	infoDays := e.thresholds.InfoDays
+
+	if discontinued {
+		result.Status = models.StatusCritical
+		result.Description = fmt.Sprintf("The %s is based on a discontinued version of %s.", subject, product)
+		result.Recommendation = fmt.Sprintf("Upgrade immediately to the latest version (%s) as this version is no longer maintained.", overallLatest)
+	} else if hasSupport && supportEndDateErr == nil && supportEndDate.Before(now) {
+		result.Status = models.StatusCritical
+		result.Description = fmt.Sprintf("The %s is based on %s which is no longer supported (support ended on %s).", subject, product, supportEndStr)
+		result.Recommendation = fmt.Sprintf("Upgrade to a supported version. Latest version is %s.", overallLatest)
+	} else if hasEOL && eolDateErr == nil && eolDate.Before(now) {
+		result.Status = models.StatusCritical
+		result.Description = fmt.Sprintf("The %s is based on %s which reached End-of-Life on %s.", subject, product, eolDateStr)
+		result.Recommendation = fmt.Sprintf("Upgrade to a newer version. Latest version is %s.", overallLatest)
+	} else if hasSupport && supportEndDateErr == nil && daysToSupportEnd <= warningDays {
+		result.Status = models.StatusWarning
+		result.Description = fmt.Sprintf("The %s is based on %s which will lose support in %d days (on %s).", subject, product, daysToSupportEnd, supportEndStr)
+		result.Recommendation = fmt.Sprintf("Plan to upgrade soon. Latest version is %s.", overallLatest)
+	} else if hasEOL && eolDateErr == nil && result.DaysRemaining <= warningDays {
+		result.Status = models.StatusWarning
+		result.Description = fmt.Sprintf("The %s is based on %s which will reach End-of-Life in %d days (on %s).", subject, product, result.DaysRemaining, eolDateStr)
+		result.Recommendation = fmt.Sprintf("Plan to upgrade soon. Latest version is %s.", overallLatest)
+	} else if (hasSupport && supportEndDateErr == nil && daysToSupportEnd <= infoDays) || (hasEOL && eolDateErr == nil && result.DaysRemaining <= infoDays) {
+		result.Status = models.StatusInfo
+		if hasSupport && supportEndDateErr == nil && daysToSupportEnd <= infoDays {
+			result.Description = fmt.Sprintf("The %s is based on %s which will lose support in %d days (on %s).", subject, product, daysToSupportEnd, supportEndStr)
+		} else {
+			result.Description = fmt.Sprintf("The %s is based on %s which will reach End-of-Life in %d days (on %s).", subject, product, result.DaysRemaining, eolDateStr)
+		}
+		result.Recommendation = fmt.Sprintf("Consider planning an upgrade. Latest version is %s.", overallLatest)
+	} else {
+		result.Status = models.StatusOK
+		result.Description = fmt.Sprintf("The %s is based on a currently supported version of %s.", subject, product)
+		if cycleInfo.Latest != string(cycleInfo.Cycle) {
+			result.Recommendation = fmt.Sprintf("This version is supported, but consider upgrading to the latest version (%s) for the newest features and security updates.", overallLatest)
+		}
+	}
+
+	return result
+}
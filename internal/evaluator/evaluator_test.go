@@ -0,0 +1,104 @@
+package evaluator
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HMZElidrissi/eol-checker/internal/models"
+	"github.com/HMZElidrissi/eol-checker/internal/version"
+	"github.com/HMZElidrissi/eol-checker/pkg/image"
+)
+
+// stubFetcher serves fixed cycles per product
+type stubFetcher map[string][]models.EOLCycle
+
+func (f stubFetcher) GetProductCycles(product string) ([]models.EOLCycle, error) {
+	if product == "unreachable" {
+		return nil, errors.New("connection refused")
+	}
+	return f[product], nil
+}
+
+func clock(date string) func() time.Time {
+	now, _ := time.Parse("2006-01-02", date)
+	return func() time.Time { return now }
+}
+
+func TestEvaluateProductStatus(t *testing.T) {
+	fetcher := stubFetcher{"nginx": {
+		{Cycle: "1.27", ReleaseDate: "2024-05-29", Latest: "1.27.2", EOL: false, Support: "2025-06-01"},
+		{Cycle: "1.24", ReleaseDate: "2023-04-11", Latest: "1.24.0", EOL: "2025-01-01"},
+		{Cycle: "1.18", ReleaseDate: "2020-04-21", Latest: "1.18.0", EOL: false, Discontinued: true},
+	}}
+
+	tests := []struct {
+		name       string
+		version    string
+		now        string
+		thresholds Thresholds
+		want       string
+		wantDays   int
+	}{
+		{name: "far from EOL", version: "1.24", now: "2024-06-01", thresholds: DefaultThresholds(), want: models.StatusOK, wantDays: 214},
+		{name: "within info days", version: "1.24", now: "2024-11-01", thresholds: DefaultThresholds(), want: models.StatusInfo, wantDays: 61},
+		{name: "within warning days", version: "1.24", now: "2024-12-15", thresholds: DefaultThresholds(), want: models.StatusWarning, wantDays: 17},
+		{name: "EOL day", version: "1.24", now: "2025-01-01", thresholds: DefaultThresholds(), want: models.StatusWarning, wantDays: 0},
+		{name: "past EOL", version: "1.24", now: "2025-02-01", thresholds: DefaultThresholds(), want: models.StatusCritical, wantDays: -31},
+		{name: "wider warning threshold", version: "1.24", now: "2024-11-01", thresholds: Thresholds{WarningDays: 90, InfoDays: 180}, want: models.StatusWarning, wantDays: 61},
+		{name: "narrower info threshold", version: "1.24", now: "2024-11-01", thresholds: Thresholds{WarningDays: 7, InfoDays: 30}, want: models.StatusOK, wantDays: 61},
+		{name: "support ending", version: "1.27", now: "2025-05-20", thresholds: DefaultThresholds(), want: models.StatusWarning, wantDays: -1},
+		{name: "support ended", version: "1.27", now: "2025-07-01", thresholds: DefaultThresholds(), want: models.StatusCritical, wantDays: -1},
+		{name: "discontinued", version: "1.18", now: "2024-06-01", thresholds: DefaultThresholds(), want: models.StatusCritical, wantDays: -1},
+		{name: "unknown version", version: "0.9", now: "2024-06-01", thresholds: DefaultThresholds(), want: models.StatusUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New(fetcher, version.NewMatcher(), image.NewParser(), WithClock(clock(tt.now)), WithThresholds(tt.thresholds))
+			evaluation, err := e.EvaluateProduct("runtime nginx", "nginx", tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := evaluation.Result
+			if result.Status != tt.want || result.DaysRemaining != tt.wantDays {
+				t.Errorf("status %s, %d days remaining, want %s, %d days (%s)", result.Status, result.DaysRemaining, tt.want, tt.wantDays, result.Description)
+			}
+			if result.Status != models.StatusUnknown && result.Latest != "1.27.2" {
+				t.Errorf("Latest = %q, want 1.27.2", result.Latest)
+			}
+		})
+	}
+}
+
+func TestEvaluateImage(t *testing.T) {
+	fetcher := stubFetcher{"nginx": {{Cycle: "1.20", ReleaseDate: "2021-05-25", Latest: "1.20.2", EOL: "2022-05-24"}}}
+	e := New(fetcher, version.NewMatcher(), image.NewParser(), WithClock(clock("2024-01-01")))
+
+	evaluation, err := e.EvaluateImage("nginx:1.20-alpine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evaluation.Image != "nginx:1.20-alpine" || evaluation.ImageInfo == nil || evaluation.Cycle == nil {
+		t.Fatalf("EvaluateImage() = %+v", evaluation)
+	}
+	if evaluation.Result.Status != models.StatusCritical || evaluation.Result.EOLDate != "2022-05-24" {
+		t.Errorf("Result = %+v, want CRITICAL with EOL date 2022-05-24", evaluation.Result)
+	}
+}
+
+func TestEvaluateProductErrors(t *testing.T) {
+	e := New(stubFetcher{}, version.NewMatcher(), image.NewParser())
+
+	evaluation, err := e.EvaluateProduct("image nothing:1", "nothing", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evaluation.Result.Status != models.StatusUnknown {
+		t.Errorf("unknown product: status %s, want UNKNOWN", evaluation.Result.Status)
+	}
+
+	_, err = e.EvaluateProduct("image unreachable:1", "unreachable", "1")
+	if !IsFetchError(err) {
+		t.Errorf("fetch failure: error %v is not a FetchError", err)
+	}
+}
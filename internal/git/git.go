@@ -0,0 +1,408 @@
+package git
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Change describes how a file differs from a base revision
+type Change struct {
+	// Path is the file's path relative to the repository root
+	Path string
+	// OldPath is the file's path in the base revision, empty for files
+	// that did not exist there
+	OldPath string
+	// Lines holds the line numbers of the added or modified lines. Every
+	// line of a new file is new.
+	Lines map[int]bool
+}
+
+// Changed reports whether line was added or modified
+func (c *Change) Changed(line int) bool {
+	return c.OldPath == "" || c.Lines[line]
+}
+
+// run runs git in dir and returns its standard output
+func run(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "core.quotePath=false"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// Toplevel returns the root of the working tree that contains dir
+func Toplevel(dir string) (string, error) {
+	out, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Verify checks that ref names a commit
+func Verify(dir, ref string) error {
+	_, err := run(dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return fmt.Errorf("unknown revision %q", ref)
+	}
+	return nil
+}
+
+// MergeBase returns the commit where HEAD branched off ref, so that changes
+// made on ref since then are not taken for changes of HEAD
+func MergeBase(dir, ref string) (string, error) {
+	out, err := run(dir, "merge-base", ref, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("no common ancestor of %q and HEAD: %w", ref, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Changes returns the files of the working tree that differ from ref,
+// including untracked files that are not ignored, keyed by their path
+// relative to the repository root. Deleted files are left out.
+func Changes(root, ref string) (map[string]*Change, error) {
+	// The prefixes are set explicitly since diff.noprefix and
+	// diff.mnemonicPrefix change them
+	out, err := run(root, "diff", "--no-color", "--no-ext-diff", "--no-textconv",
+		"--src-prefix=a/", "--dst-prefix=b/", "-U0", "-M", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	changes := parseDiff(out)
+
+	out, err = run(root, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range strings.Split(string(out), "\x00") {
+		if path != "" {
+			changes[path] = &Change{Path: path}
+		}
+	}
+	return changes, nil
+}
+
+// parseDiff reads the files and added lines of a unified diff without
+// context, with a/ and b/ prefixes
+func parseDiff(diff []byte) map[string]*Change {
+	changes := map[string]*Change{}
+	var current *Change
+	var oldPath string
+	sc := bufio.NewScanner(bytes.NewReader(diff))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current, oldPath = nil, ""
+		case strings.HasPrefix(line, "--- "):
+			if name := diffPath(strings.TrimPrefix(line, "--- ")); name != "/dev/null" {
+				oldPath = strings.TrimPrefix(name, "a/")
+			}
+		case strings.HasPrefix(line, "+++ "):
+			name := diffPath(strings.TrimPrefix(line, "+++ "))
+			if name == "/dev/null" {
+				// Deleted file
+				continue
+			}
+			path := strings.TrimPrefix(name, "b/")
+			current = &Change{Path: path, OldPath: oldPath, Lines: map[int]bool{}}
+			changes[path] = current
+		case strings.HasPrefix(line, "rename from "):
+			oldPath = diffPath(strings.TrimPrefix(line, "rename from "))
+		case strings.HasPrefix(line, "rename to "):
+			// A pure rename has no hunks, so record it here
+			path := diffPath(strings.TrimPrefix(line, "rename to "))
+			current = &Change{Path: path, OldPath: oldPath, Lines: map[int]bool{}}
+			changes[path] = current
+		case strings.HasPrefix(line, "@@ ") && current != nil:
+			start, count := parseHunkHeader(line)
+			for i := 0; i < count; i++ {
+				current.Lines[start+i] = true
+			}
+		}
+	}
+	return changes
+}
+
+// diffPath decodes a path of a diff header. Git quotes paths with special
+// characters, and ends the ones with spaces with a tab.
+func diffPath(name string) string {
+	if strings.HasPrefix(name, `"`) {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			return unquoted
+		}
+	}
+	return strings.TrimSuffix(name, "\t")
+}
+
+// parseHunkHeader returns the first line and the number of lines of the new
+// side of a hunk header such as "@@ -3,2 +3,4 @@"
+func parseHunkHeader(header string) (start, count int) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0
+	}
+	newRange := strings.TrimPrefix(fields[2], "+")
+	first, length, hasLength := strings.Cut(newRange, ",")
+	start, _ = strconv.Atoi(first)
+	count = 1
+	if hasLength {
+		count, _ = strconv.Atoi(length)
+	}
+	return start, count
+}
+
+// Export writes the tree of ref into dest
+func Export(root, ref, dest string) error {
+	cmd := exec.Command("git", "-C", root, "archive", "--format=tar", ref)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	extractErr := extract(stdout, dest)
+	// Drain the archive so git can exit when extraction stopped early
+	io.Copy(io.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("git archive: %s", msg)
+		}
+		return fmt.Errorf("git archive: %w", err)
+	}
+	return extractErr
+}
+
+// extract unpacks the directories, files and symlinks of a tar stream
+func extract(r io.Reader, dest string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dest, filepath.FromSlash(hdr.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dest)+string(filepath.Separator)) {
+			return fmt.Errorf("invalid path %q in archive", hdr.Name)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Commit identifies a commit and its author
+type Commit struct {
+	Hash    string
+	Author  string
+	Email   string
+	Time    time.Time
+	Summary string
+}
+
+// BlameLine is the commit that last changed a line, ignoring whitespace and
+// moves, and where the line was in that commit
+type BlameLine struct {
+	Commit
+	// Path is relative to the repository root
+	Path string
+	Line int
+}
+
+// Blame returns the commit that last changed each line of the file at path,
+// keyed by line number. Changes to whitespace and lines moved or copied from
+// other files are looked through. Lines that are not committed yet are left
+// out.
+func Blame(path string) (map[int]BlameLine, error) {
+	out, err := run(filepath.Dir(path), "blame", "-w", "-M", "-C", "--line-porcelain", "--", filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	return parseBlame(out), nil
+}
+
+// parseBlame reads the output of git blame --line-porcelain
+func parseBlame(out []byte) map[int]BlameLine {
+	lines := map[int]BlameLine{}
+	var current BlameLine
+	lineNo := 0
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "\t") {
+			// The content of the line ends its record
+			// Lines not committed yet are attributed to an all-zero commit
+			if strings.Trim(current.Hash, "0") != "" {
+				lines[lineNo] = current
+			}
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			current.Author = value
+		case "author-mail":
+			current.Email = strings.Trim(value, "<>")
+		case "author-time":
+			if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+				current.Time = time.Unix(seconds, 0).UTC()
+			}
+		case "summary":
+			current.Summary = value
+		case "filename":
+			current.Path = diffPath(value)
+		default:
+			// Records start with "<commit> <original line> <final line>"
+			fields := strings.Fields(line)
+			if len(fields) >= 3 && isHex(fields[0]) && len(fields[0]) >= 40 {
+				current = BlameLine{Commit: Commit{Hash: fields[0]}}
+				current.Line, _ = strconv.Atoi(fields[1])
+				lineNo, _ = strconv.Atoi(fields[2])
+			}
+		}
+	}
+	return lines
+}
+
+// Introduced returns the commit that introduced text on a line, following
+// the history of the line back from the commit that last changed it while
+// the line kept text. It is that commit when the line never held text
+// before. root is the repository root.
+func Introduced(root string, line BlameLine, text string) (Commit, error) {
+	if text == "" {
+		return line.Commit, nil
+	}
+	out, err := run(root, "log", "--no-color", "--no-ext-diff",
+		"--format=commit %H%x00%an%x00%ae%x00%at%x00%s",
+		fmt.Sprintf("-L%d,%d:%s", line.Line, line.Line, line.Path), line.Hash)
+	if err != nil {
+		return Commit{}, err
+	}
+	if introduced, ok := parseLineLog(out, text); ok {
+		return introduced, nil
+	}
+	return line.Commit, nil
+}
+
+// lineChange is a commit of git log -L with the line before and after it
+type lineChange struct {
+	Commit
+	before, after []string
+}
+
+// parseLineLog finds, in the output of git log -L for a single line, the
+// commit that introduced text: the oldest of the commits, newest first, that
+// kept text on the line. ok is false when the newest commit doesn't have it.
+func parseLineLog(out []byte, text string) (introduced Commit, ok bool) {
+	var changes []*lineChange
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	inHunk := false
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "commit "):
+			fields := strings.SplitN(strings.TrimPrefix(line, "commit "), "\x00", 5)
+			if len(fields) < 5 {
+				continue
+			}
+			change := &lineChange{Commit: Commit{Hash: fields[0], Author: fields[1], Email: fields[2], Summary: fields[4]}}
+			if seconds, err := strconv.ParseInt(fields[3], 10, 64); err == nil {
+				change.Time = time.Unix(seconds, 0).UTC()
+			}
+			changes = append(changes, change)
+			inHunk = false
+		case strings.HasPrefix(line, "@@ "):
+			inHunk = true
+		case inHunk && len(changes) > 0:
+			change := changes[len(changes)-1]
+			if strings.HasPrefix(line, "-") {
+				change.before = append(change.before, line[1:])
+			} else if strings.HasPrefix(line, "+") {
+				change.after = append(change.after, line[1:])
+			}
+		}
+	}
+
+	contains := func(lines []string) bool {
+		for _, l := range lines {
+			if strings.Contains(l, text) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, change := range changes {
+		if !contains(change.after) {
+			break
+		}
+		introduced, ok = change.Commit, true
+		if !contains(change.before) {
+			break
+		}
+	}
+	return introduced, ok
+}
+
+// isHex reports whether s is made of hexadecimal digits
+func isHex(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,460 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseHunkHeader(t *testing.T) {
+	tests := []struct {
+		header       string
+		start, count int
+	}{
+		{"@@ -3,2 +3,4 @@", 3, 4},
+		{"@@ -3 +3 @@", 3, 1},
+		{"@@ -0,0 +1 @@", 1, 1},
+		{"@@ -0,0 +1,12 @@", 1, 12},
+		{"@@ -2,2 +1,0 @@", 1, 0},
+		{"@@ -5 +4,0 @@ func main() {", 4, 0},
+		{"@@ -10,3 +12,2 @@ section heading", 12, 2},
+		{"@@ malformed", 0, 0},
+	}
+	for _, tt := range tests {
+		start, count := parseHunkHeader(tt.header)
+		if start != tt.start || count != tt.count {
+			t.Errorf("parseHunkHeader(%q) = %d, %d, want %d, %d", tt.header, start, count, tt.start, tt.count)
+		}
+	}
+}
+
+func TestParseDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		diff string
+		want map[string]*Change
+	}{
+		{
+			name: "modified lines",
+			diff: `diff --git a/Dockerfile b/Dockerfile
+index 94ebaf9..5da50a4 100644
+--- a/Dockerfile
++++ b/Dockerfile
+@@ -1 +1 @@
+-FROM node:16
++FROM node:18
+@@ -4,0 +5,2 @@
++FROM python:3.8
++RUN pip install .
+`,
+			want: map[string]*Change{
+				"Dockerfile": {Path: "Dockerfile", OldPath: "Dockerfile", Lines: map[int]bool{1: true, 5: true, 6: true}},
+			},
+		},
+		{
+			name: "deleted lines only",
+			diff: `diff --git a/del.txt b/del.txt
+--- a/del.txt
++++ b/del.txt
+@@ -2,2 +1,0 @@
+-2
+-3
+`,
+			want: map[string]*Change{
+				"del.txt": {Path: "del.txt", OldPath: "del.txt", Lines: map[int]bool{}},
+			},
+		},
+		{
+			name: "new file",
+			diff: `diff --git a/compose.yaml b/compose.yaml
+new file mode 100644
+index 0000000..bca70f3
+--- /dev/null
++++ b/compose.yaml
+@@ -0,0 +1,2 @@
++services:
++  web: {image: nginx:1.20}
+`,
+			want: map[string]*Change{
+				"compose.yaml": {Path: "compose.yaml", Lines: map[int]bool{1: true, 2: true}},
+			},
+		},
+		{
+			name: "deleted file",
+			diff: `diff --git a/gone.yaml b/gone.yaml
+deleted file mode 100644
+--- a/gone.yaml
++++ /dev/null
+@@ -1 +0,0 @@
+-image: nginx
+`,
+			want: map[string]*Change{},
+		},
+		{
+			name: "pure rename",
+			diff: `diff --git a/old.txt b/new name.txt
+similarity index 100%
+rename from old.txt
+rename to new name.txt
+`,
+			want: map[string]*Change{
+				"new name.txt": {Path: "new name.txt", OldPath: "old.txt", Lines: map[int]bool{}},
+			},
+		},
+		{
+			name: "rename with changes",
+			diff: `diff --git a/k8s/web.yaml b/deploy/web.yaml
+similarity index 80%
+rename from k8s/web.yaml
+rename to deploy/web.yaml
+index 1111111..2222222 100644
+--- a/k8s/web.yaml
++++ b/deploy/web.yaml
+@@ -3 +3 @@
+-image: nginx:1.20
++image: nginx:1.25
+`,
+			want: map[string]*Change{
+				"deploy/web.yaml": {Path: "deploy/web.yaml", OldPath: "k8s/web.yaml", Lines: map[int]bool{3: true}},
+			},
+		},
+		{
+			name: "path with spaces",
+			diff: "diff --git a/my dir/Dockerfile b/my dir/Dockerfile\n" +
+				"--- a/my dir/Dockerfile\t\n" +
+				"+++ b/my dir/Dockerfile\t\n" +
+				"@@ -2 +2 @@\n" +
+				"-FROM node:16\n" +
+				"+FROM node:18\n",
+			want: map[string]*Change{
+				"my dir/Dockerfile": {Path: "my dir/Dockerfile", OldPath: "my dir/Dockerfile", Lines: map[int]bool{2: true}},
+			},
+		},
+		{
+			name: "quoted path",
+			diff: `diff --git "a/we\"ird.txt" "b/we\"ird.txt"
+new file mode 100644
+--- /dev/null
++++ "b/we\"ird.txt"
+@@ -0,0 +1 @@
++q
+`,
+			want: map[string]*Change{
+				`we"ird.txt`: {Path: `we"ird.txt`, Lines: map[int]bool{1: true}},
+			},
+		},
+		{
+			name: "several files",
+			diff: `diff --git a/a.txt b/a.txt
+--- a/a.txt
++++ b/a.txt
+@@ -1 +1 @@
+-x
++y
+diff --git a/b.txt b/b.txt
+--- a/b.txt
++++ b/b.txt
+@@ -7,0 +8 @@
++z
+`,
+			want: map[string]*Change{
+				"a.txt": {Path: "a.txt", OldPath: "a.txt", Lines: map[int]bool{1: true}},
+				"b.txt": {Path: "b.txt", OldPath: "b.txt", Lines: map[int]bool{8: true}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseDiff([]byte(tt.diff))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseDiff() = %v, want %v", describe(got), describe(tt.want))
+			}
+		})
+	}
+}
+
+func describe(changes map[string]*Change) map[string]Change {
+	out := map[string]Change{}
+	for path, change := range changes {
+		out[path] = *change
+	}
+	return out
+}
+
+// repository creates a git repository with the given configuration
+func repository(t *testing.T, config ...string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	gitCommand(t, dir, "init", "-q")
+	gitCommand(t, dir, "config", "user.name", "Test")
+	gitCommand(t, dir, "config", "user.email", "test@example.com")
+	gitCommand(t, dir, "config", "commit.gpgsign", "false")
+	for i := 0; i+1 < len(config); i += 2 {
+		gitCommand(t, dir, "config", config[i], config[i+1])
+	}
+	return dir
+}
+
+func gitCommand(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return string(out)
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChangesIgnoresPrefixConfig(t *testing.T) {
+	configs := map[string][]string{
+		"default":         nil,
+		"mnemonic prefix": {"diff.mnemonicPrefix", "true"},
+		"no prefix":       {"diff.noprefix", "true"},
+	}
+	for name, config := range configs {
+		t.Run(name, func(t *testing.T) {
+			dir := repository(t, config...)
+			writeFile(t, dir, "app/Dockerfile", "FROM node:16\nRUN npm ci\n")
+			gitCommand(t, dir, "add", "-A")
+			gitCommand(t, dir, "commit", "-qm", "init")
+
+			writeFile(t, dir, "app/Dockerfile", "FROM node:18\nRUN npm ci\n")
+			writeFile(t, dir, "new.yaml", "image: nginx\n")
+			changes, err := Changes(dir, "HEAD")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[string]*Change{
+				"app/Dockerfile": {Path: "app/Dockerfile", OldPath: "app/Dockerfile", Lines: map[int]bool{1: true}},
+				"new.yaml":       {Path: "new.yaml"},
+			}
+			if !reflect.DeepEqual(changes, want) {
+				t.Errorf("Changes() = %v, want %v", describe(changes), describe(want))
+			}
+		})
+	}
+}
+
+func TestMergeBase(t *testing.T) {
+	dir := repository(t)
+	writeFile(t, dir, "Dockerfile", "FROM node:16\n")
+	gitCommand(t, dir, "add", "-A")
+	gitCommand(t, dir, "commit", "-qm", "init")
+	gitCommand(t, dir, "branch", "-M", "main")
+	fork := gitCommand(t, dir, "rev-parse", "HEAD")
+
+	gitCommand(t, dir, "checkout", "-qb", "feature")
+	writeFile(t, dir, "compose.yaml", "services: {}\n")
+	gitCommand(t, dir, "add", "-A")
+	gitCommand(t, dir, "commit", "-qm", "feature")
+
+	// main moves on after the branch point
+	gitCommand(t, dir, "checkout", "-q", "main")
+	writeFile(t, dir, "Dockerfile", "FROM node:20\n")
+	gitCommand(t, dir, "commit", "-qam", "upgrade")
+	gitCommand(t, dir, "checkout", "-q", "feature")
+
+	base, err := MergeBase(dir, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base+"\n" != fork {
+		t.Errorf("MergeBase() = %s, want %s", base, fork)
+	}
+	changes, err := Changes(dir, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes["compose.yaml"] == nil {
+		t.Errorf("Changes() = %v, want only compose.yaml", describe(changes))
+	}
+}
+
+func TestParseBlame(t *testing.T) {
+	out := "b99e4e0174b5cc6b13f528a77766e1970ec46f42 3 4 1\n" +
+		"author Jane Doe\n" +
+		"author-mail <jane@example.com>\n" +
+		"author-time 1676332800\n" +
+		"author-tz +0000\n" +
+		"summary Pin node\n" +
+		"previous 2bbe5cca1380b07ac2030457066f2fd2a1b38454 app.Dockerfile\n" +
+		"filename app.Dockerfile\n" +
+		"\tFROM node:16 AS build\n" +
+		"0000000000000000000000000000000000000000 5 5 1\n" +
+		"author Not Committed Yet\n" +
+		"author-mail <not.committed.yet>\n" +
+		"author-time 1700000000\n" +
+		"summary Version of app.Dockerfile from app.Dockerfile\n" +
+		"filename app.Dockerfile\n" +
+		"\tFROM python:3.8\n" +
+		"d0a9432fcbbefcc8b6b6243f535964dc35d86975 1 6 1\n" +
+		"author John Roe\n" +
+		"author-mail <john@example.com>\n" +
+		"author-time 1600000000\n" +
+		"summary Copy from base\n" +
+		"filename base/my file\n" +
+		"\tFROM alpine:3.12\n"
+
+	want := map[int]BlameLine{
+		4: {
+			Commit: Commit{
+				Hash:    "b99e4e0174b5cc6b13f528a77766e1970ec46f42",
+				Author:  "Jane Doe",
+				Email:   "jane@example.com",
+				Time:    time.Unix(1676332800, 0).UTC(),
+				Summary: "Pin node",
+			},
+			Path: "app.Dockerfile",
+			Line: 3,
+		},
+		6: {
+			Commit: Commit{
+				Hash:    "d0a9432fcbbefcc8b6b6243f535964dc35d86975",
+				Author:  "John Roe",
+				Email:   "john@example.com",
+				Time:    time.Unix(1600000000, 0).UTC(),
+				Summary: "Copy from base",
+			},
+			Path: "base/my file",
+			Line: 1,
+		},
+	}
+	if got := parseBlame([]byte(out)); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseBlame() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseLineLog(t *testing.T) {
+	log := func(commits ...string) []byte {
+		return []byte(strings.Join(commits, "\n") + "\n")
+	}
+	commit := func(hash, before, after string) string {
+		lines := []string{
+			"commit " + hash + "\x00Dev\x00dev@example.com\x001600000000\x00change " + hash,
+			"",
+			"diff --git a/Dockerfile b/Dockerfile",
+		}
+		if before == "" {
+			lines = append(lines, "--- /dev/null", "+++ b/Dockerfile", "@@ -0,0 +2,1 @@")
+		} else {
+			lines = append(lines, "--- a/Dockerfile", "+++ b/Dockerfile", "@@ -2,1 +2,1 @@", "-"+before)
+		}
+		return strings.Join(append(lines, "+"+after), "\n")
+	}
+
+	tests := []struct {
+		name   string
+		out    []byte
+		text   string
+		want   string
+		wantOK bool
+	}{
+		{
+			name: "added in the first commit",
+			out:  log(commit("c1", "", "FROM node:16")),
+			text: "node:16", want: "c1", wantOK: true,
+		},
+		{
+			name: "reformatted later",
+			out: log(
+				commit("c3", "FROM  node:16", "FROM  node:16 AS build"),
+				commit("c2", "FROM node:16", "FROM  node:16"),
+				commit("c1", "", "FROM node:16"),
+			),
+			text: "node:16", want: "c1", wantOK: true,
+		},
+		{
+			name: "upgraded",
+			out: log(
+				commit("c3", "FROM node:16 AS build", "FROM node:18 AS build"),
+				commit("c2", "FROM node:16", "FROM node:16 AS build"),
+				commit("c1", "", "FROM node:16"),
+			),
+			text: "node:18", want: "c3", wantOK: true,
+		},
+		{
+			name: "reformatted after upgrade",
+			out: log(
+				commit("c3", "FROM node:18", "FROM node:18 AS build"),
+				commit("c2", "FROM node:16", "FROM node:18"),
+				commit("c1", "", "FROM node:16"),
+			),
+			text: "node:18", want: "c2", wantOK: true,
+		},
+		{
+			name: "text not on the line",
+			out:  log(commit("c1", "", "  tag: \"1.20\"")),
+			text: "nginx:1.20",
+		},
+		{
+			name: "no commits",
+			text: "node:16",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseLineLog(tt.out, tt.text)
+			if ok != tt.wantOK || got.Hash != tt.want {
+				t.Errorf("parseLineLog() = %q, %v, want %q, %v", got.Hash, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestIntroduced(t *testing.T) {
+	dir := repository(t)
+	commit := func(name, content, message string) {
+		writeFile(t, dir, name, content)
+		gitCommand(t, dir, "add", "-A")
+		gitCommand(t, dir, "commit", "-qm", message)
+	}
+	commit("Dockerfile", "ARG X\nFROM node:16\n", "add node")
+	commit("Dockerfile", "ARG X\nFROM  node:16\n", "reformat")
+	gitCommand(t, dir, "mv", "Dockerfile", "app.Dockerfile")
+	gitCommand(t, dir, "commit", "-qm", "rename")
+	commit("app.Dockerfile", "# build\nARG X\nFROM  node:16 AS build\nFROM python:3.8\n", "name stage")
+
+	blame, err := Blame(filepath.Join(dir, "app.Dockerfile"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		line int
+		text string
+		want string
+	}{
+		{3, "node:16", "add node"},
+		{4, "python:3.8", "name stage"},
+		{3, "", "name stage"},
+	}
+	for _, tt := range tests {
+		line, ok := blame[tt.line]
+		if !ok {
+			t.Fatalf("no blame for line %d", tt.line)
+		}
+		commit, err := Introduced(dir, line, tt.text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if commit.Summary != tt.want {
+			t.Errorf("Introduced(line %d, %q) = %q, want %q", tt.line, tt.text, commit.Summary, tt.want)
+		}
+	}
+}
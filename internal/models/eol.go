@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // CycleString handles both string and numeric cycle values from the API
@@ -57,3 +58,27 @@ const (
 	StatusOK       = "OK"
 	StatusUnknown  = "UNKNOWN"
 )
+
+// OrderedStatuses lists the ranked statuses from least to most severe.
+// UNKNOWN is not ranked since it carries no lifecycle information.
+var OrderedStatuses = []string{StatusOK, StatusInfo, StatusWarning, StatusCritical}
+
+// Severity returns the rank of a status in OrderedStatuses, or -1 for
+// UNKNOWN and unrecognized statuses
+func Severity(status string) int {
+	for i, s := range OrderedStatuses {
+		if s == status {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParseStatus returns the ranked status matching name, ignoring case
+func ParseStatus(name string) (string, error) {
+	status := strings.ToUpper(name)
+	if Severity(status) < 0 {
+		return "", fmt.Errorf("invalid status %q (expected one of %s)", name, strings.Join(OrderedStatuses, ", "))
+	}
+	return status, nil
+}
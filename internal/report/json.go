@@ -0,0 +1,27 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// writeJSON writes all entries as a single indented JSON array
+func writeJSON(w io.Writer, entries []Entry) error {
+	if entries == nil {
+		entries = []Entry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+// writeNDJSON writes one JSON object per line
+func writeNDJSON(w io.Writer, entries []Entry) error {
+	enc := json.NewEncoder(w)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/HMZElidrissi/eol-checker/internal/models"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",cdata"`
+}
+
+// writeJUnit writes entries as JUnit XML. Each entry is a testcase, grouped
+// into testsuites by source file, or by product when it has no file.
+func writeJUnit(w io.Writer, entries []Entry, opts Options) error {
+	root := junitTestSuites{Name: toolName}
+	suiteIndex := make(map[string]int)
+
+	for _, entry := range entries {
+		suiteName := junitSuiteName(entry)
+		index, ok := suiteIndex[suiteName]
+		if !ok {
+			index = len(root.Suites)
+			suiteIndex[suiteName] = index
+			root.Suites = append(root.Suites, junitTestSuite{Name: suiteName})
+		}
+		suite := &root.Suites[index]
+
+		testCase := junitTestCaseFor(entry, opts)
+		suite.Tests++
+		switch {
+		case testCase.Failure != nil:
+			suite.Failures++
+		case testCase.Error != nil:
+			suite.Errors++
+		case testCase.Skipped != nil:
+			suite.Skipped++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	for _, suite := range root.Suites {
+		root.Tests += suite.Tests
+		root.Failures += suite.Failures
+		root.Errors += suite.Errors
+		root.Skipped += suite.Skipped
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitSuiteName(entry Entry) string {
+	switch {
+	case entry.Location != nil && entry.Location.File != "":
+		return entry.Location.File
+	case entry.Result != nil:
+		return entry.Result.Product
+	case entry.ImageInfo != nil:
+		return entry.ImageInfo.Product
+	default:
+		return entry.Name()
+	}
+}
+
+func junitTestCaseFor(entry Entry, opts Options) junitTestCase {
+	testCase := junitTestCase{Name: entry.Name()}
+	if entry.Location != nil && entry.Location.Line > 0 {
+		testCase.Name = fmt.Sprintf("%s (line %d)", entry.Name(), entry.Location.Line)
+	}
+
+	if entry.Error != "" {
+		testCase.Classname = entry.Name()
+		testCase.Error = &junitMessage{Message: entry.Error, Type: "error"}
+		return testCase
+	}
+
+	result := entry.Result
+	testCase.Classname = result.Product
+	if result.Version != "" {
+		testCase.Classname = result.Product + "." + result.Version
+	}
+
+	switch {
+	case result.Status == models.StatusCritical,
+		result.Status == models.StatusWarning && opts.WarningsAsFailures:
+		testCase.Failure = &junitMessage{
+			Message: strings.TrimSpace(result.Description + " " + result.Recommendation),
+			Type:    result.Status,
+			Text:    junitDetails(entry),
+		}
+	case result.Status == models.StatusUnknown:
+		testCase.Skipped = &junitMessage{Message: result.Description}
+	default:
+		testCase.SystemOut = &junitOutput{Text: junitDetails(entry)}
+	}
+	return testCase
+}
+
+func junitDetails(entry Entry) string {
+	result := entry.Result
+	lines := []string{result.Description}
+	if result.Recommendation != "" {
+		lines = append(lines, result.Recommendation)
+	}
+	if entry.Blame != nil {
+		lines = append(lines, "Introduced: "+blameText(entry.Blame))
+	}
+	if result.Link != "" {
+		lines = append(lines, "More info: "+result.Link)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,131 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/HMZElidrissi/eol-checker/internal/evaluator"
+	"github.com/HMZElidrissi/eol-checker/internal/models"
+	"github.com/HMZElidrissi/eol-checker/pkg/image"
+)
+
+// Format is an output format for check results
+type Format string
+
+// Supported formats
+const (
+	FormatText   Format = "text"
+	FormatJSON   Format = "json"
+	FormatNDJSON Format = "ndjson"
+	FormatSARIF  Format = "sarif"
+	FormatJUnit  Format = "junit"
+)
+
+// Formats lists the supported formats in the order they are documented
+var Formats = []Format{FormatText, FormatJSON, FormatNDJSON, FormatSARIF, FormatJUnit}
+
+// ParseFormat validates a format name
+func ParseFormat(name string) (Format, error) {
+	for _, f := range Formats {
+		if string(f) == strings.ToLower(name) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q (supported: %s)", name, formatNames())
+}
+
+func formatNames() string {
+	names := make([]string, len(Formats))
+	for i, f := range Formats {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+// Location is the place in a file an image reference was found
+type Location struct {
+	File string `json:"file"`
+	Line int    `json:"line,omitempty"`
+}
+
+// Entry is the outcome of checking a single image reference
+type Entry struct {
+	Image string `json:"image,omitempty"`
+	// Subject names what was checked when it is not an image, such as
+	// "runner ubuntu-20.04"
+	Subject string `json:"subject,omitempty"`
+	// Raw is the reference as written in the source file, when variable
+	// substitution changed it
+	Raw        string            `json:"raw,omitempty"`
+	Unresolved []string          `json:"unresolved,omitempty"`
+	Location   *Location         `json:"location,omitempty"`
+	Context    map[string]string `json:"context,omitempty"`
+	ImageInfo  *image.ImageInfo  `json:"imageInfo,omitempty"`
+	Cycle      *models.EOLCycle  `json:"cycle,omitempty"`
+	Result     *models.EOLResult `json:"result,omitempty"`
+	Blame      *Blame            `json:"blame,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	// FetchFailed tells that Error comes from fetching EOL data, rather
+	// than from the file or the reference
+	FetchFailed bool `json:"-"`
+}
+
+// Blame is the commit that introduced a reference
+type Blame struct {
+	Commit  string `json:"commit"`
+	Author  string `json:"author"`
+	Email   string `json:"email,omitempty"`
+	Date    string `json:"date"`
+	Summary string `json:"summary,omitempty"`
+	// DaysPastEOL is the number of days between the EOL date and the
+	// commit, negative when the commit came first. It is nil when the
+	// release has no EOL date.
+	DaysPastEOL *int `json:"daysPastEolAtIntroduction,omitempty"`
+}
+
+// NewEntry builds an entry from an evaluation or the error that prevented it
+func NewEntry(imageName string, evaluation *evaluator.Evaluation, err error) Entry {
+	entry := Entry{Image: imageName}
+	if err != nil {
+		entry.Error = err.Error()
+		entry.FetchFailed = evaluator.IsFetchError(err)
+		return entry
+	}
+	entry.ImageInfo = evaluation.ImageInfo
+	entry.Cycle = evaluation.Cycle
+	entry.Result = &evaluation.Result
+	return entry
+}
+
+// Options tunes how entries are rendered
+type Options struct {
+	// WarningsAsFailures reports WARNING results as JUnit failures instead of passes
+	WarningsAsFailures bool
+}
+
+// Name returns the image, or the subject for entries that are not images
+func (e Entry) Name() string {
+	if e.Image != "" {
+		return e.Image
+	}
+	return e.Subject
+}
+
+// Write writes entries to w in the given format
+func Write(w io.Writer, format Format, entries []Entry, opts Options) error {
+	switch format {
+	case FormatText:
+		return writeText(w, entries)
+	case FormatJSON:
+		return writeJSON(w, entries)
+	case FormatNDJSON:
+		return writeNDJSON(w, entries)
+	case FormatSARIF:
+		return writeSARIF(w, entries)
+	case FormatJUnit:
+		return writeJUnit(w, entries, opts)
+	default:
+		return fmt.Errorf("unknown output format %q", format)
+	}
+}
@@ -0,0 +1,275 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/HMZElidrissi/eol-checker/internal/models"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "eol-checker"
+	toolURI      = "https://github.com/HMZElidrissi/eol-checker"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level      string            `json:"level"`
+	Message    sarifText         `json:"message"`
+	Locations  []sarifLocation   `json:"locations,omitempty"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifText          `json:"shortDescription"`
+	Help                 sarifHelp          `json:"help"`
+	HelpURI              string             `json:"helpUri,omitempty"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+	Properties           sarifRuleProps     `json:"properties"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifHelp struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleProps struct {
+	Product string   `json:"product"`
+	Cycle   string   `json:"cycle"`
+	Tags    []string `json:"tags"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	RuleIndex  int               `json:"ruleIndex"`
+	Kind       string            `json:"kind"`
+	Level      string            `json:"level"`
+	Message    sarifText         `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// sarifLevel maps a result status to a SARIF level. Statuses without a level
+// (UNKNOWN) are reported as notifications instead of results.
+func sarifLevel(status string) (string, bool) {
+	switch status {
+	case models.StatusCritical:
+		return "error", true
+	case models.StatusWarning:
+		return "warning", true
+	case models.StatusInfo:
+		return "note", true
+	case models.StatusOK:
+		return "none", true
+	default:
+		return "", false
+	}
+}
+
+// writeSARIF writes entries as a SARIF 2.1.0 log with one rule per product cycle
+func writeSARIF(w io.Writer, entries []Entry) error {
+	driver := sarifDriver{
+		Name:           toolName,
+		InformationURI: toolURI,
+		Rules:          []sarifRule{},
+	}
+	results := []sarifResult{}
+	ruleIndex := make(map[string]int)
+
+	// References that could not be checked are reported as notifications,
+	// so that a failed run is not read as one where every alert was fixed
+	invocation := sarifInvocation{ExecutionSuccessful: true}
+
+	for _, entry := range entries {
+		if entry.Result == nil {
+			invocation.ExecutionSuccessful = false
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications,
+				newSARIFNotification(entry, "error", entry.Error))
+			continue
+		}
+		result := entry.Result
+		level, ok := sarifLevel(result.Status)
+		if !ok {
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications,
+				newSARIFNotification(entry, "warning", result.Description))
+			continue
+		}
+
+		ruleID := fmt.Sprintf("eol/%s/%s", result.Product, result.Version)
+		index, exists := ruleIndex[ruleID]
+		if !exists {
+			index = len(driver.Rules)
+			ruleIndex[ruleID] = index
+			driver.Rules = append(driver.Rules, newSARIFRule(ruleID, level, result))
+		}
+
+		kind := "fail"
+		if result.Status == models.StatusOK {
+			kind = "pass"
+		}
+
+		properties := map[string]string{"subject": entry.Name()}
+		if entry.Blame != nil {
+			properties["introducedIn"] = entry.Blame.Commit
+			properties["introducedBy"] = entry.Blame.Author
+			properties["introducedOn"] = entry.Blame.Date
+		}
+		results = append(results, sarifResult{
+			RuleID:     ruleID,
+			RuleIndex:  index,
+			Kind:       kind,
+			Level:      level,
+			Message:    sarifText{Text: result.Description},
+			Locations:  []sarifLocation{sarifLocationFor(entry)},
+			Properties: properties,
+		})
+	}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool:        sarifTool{Driver: driver},
+			Invocations: []sarifInvocation{invocation},
+			Results:     results,
+		}},
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// newSARIFNotification reports an entry that has no lifecycle result
+func newSARIFNotification(entry Entry, level, message string) sarifNotification {
+	if name := entry.Name(); name != "" {
+		message = name + ": " + message
+	}
+	notification := sarifNotification{
+		Level:   level,
+		Message: sarifText{Text: message},
+	}
+	if entry.Location != nil && entry.Location.File != "" {
+		notification.Locations = []sarifLocation{sarifLocationFor(entry)}
+	}
+	if entry.Name() != "" {
+		notification.Properties = map[string]string{"subject": entry.Name()}
+	}
+	return notification
+}
+
+func newSARIFRule(id, level string, result *models.EOLResult) sarifRule {
+	var text, markdown strings.Builder
+	if result.Recommendation != "" {
+		text.WriteString(result.Recommendation)
+		markdown.WriteString(result.Recommendation)
+	}
+	if result.Link != "" {
+		if text.Len() > 0 {
+			text.WriteString("\n\n")
+			markdown.WriteString("\n\n")
+		}
+		fmt.Fprintf(&text, "More info: %s", result.Link)
+		fmt.Fprintf(&markdown, "[More info](%s)", result.Link)
+	}
+	if text.Len() == 0 {
+		fmt.Fprintf(&text, "%s %s is a currently supported release.", result.Product, result.Version)
+		markdown.WriteString(text.String())
+	}
+
+	return sarifRule{
+		ID:                   id,
+		Name:                 "EOLBaseImage",
+		ShortDescription:     sarifText{Text: fmt.Sprintf("%s %s lifecycle status", result.Product, result.Version)},
+		Help:                 sarifHelp{Text: text.String(), Markdown: markdown.String()},
+		HelpURI:              result.Link,
+		DefaultConfiguration: sarifConfiguration{Level: level},
+		Properties: sarifRuleProps{
+			Product: result.Product,
+			Cycle:   result.Version,
+			Tags:    []string{"eol", result.Product},
+		},
+	}
+}
+
+// sarifLocationFor points at the file line the image came from, or falls back
+// to a logical location naming the image
+func sarifLocationFor(entry Entry) sarifLocation {
+	if entry.Location == nil || entry.Location.File == "" {
+		return sarifLocation{
+			LogicalLocations: []sarifLogicalLocation{{Name: entry.Name(), Kind: "resource"}},
+		}
+	}
+
+	physical := &sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(entry.Location.File)},
+	}
+	if entry.Location.Line > 0 {
+		physical.Region = &sarifRegion{StartLine: entry.Location.Line}
+	}
+	return sarifLocation{PhysicalLocation: physical}
+}
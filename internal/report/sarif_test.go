@@ -0,0 +1,109 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/HMZElidrissi/eol-checker/internal/models"
+)
+
+func TestWriteSARIFNotifications(t *testing.T) {
+	critical := Entry{
+		Image:    "nginx:1.20",
+		Location: &Location{File: "Dockerfile", Line: 1},
+		Result:   &models.EOLResult{Product: "nginx", Version: "1.20", Status: models.StatusCritical, Description: "EOL"},
+	}
+	unknown := Entry{
+		Image:  "acme/app:1",
+		Result: &models.EOLResult{Product: "acme/app", Version: "1", Status: models.StatusUnknown, Description: "Product 'acme/app' not found in EOL database"},
+	}
+	fetchError := Entry{Image: "node:18", Location: &Location{File: "compose.yaml", Line: 4}, Error: "failed to fetch EOL data: timeout", FetchFailed: true}
+	fileError := Entry{Location: &Location{File: "Dockerfile.bad"}, Error: "line 2: invalid FROM instruction"}
+
+	tests := []struct {
+		name          string
+		entries       []Entry
+		successful    bool
+		results       int
+		notifications []string
+	}{
+		{
+			name:       "results only",
+			entries:    []Entry{critical},
+			successful: true,
+			results:    1,
+		},
+		{
+			name:          "unknown product",
+			entries:       []Entry{critical, unknown},
+			successful:    true,
+			results:       1,
+			notifications: []string{"warning"},
+		},
+		{
+			name:          "API down",
+			entries:       []Entry{fetchError},
+			successful:    false,
+			notifications: []string{"error"},
+		},
+		{
+			name:          "unreadable file",
+			entries:       []Entry{critical, fileError},
+			successful:    false,
+			results:       1,
+			notifications: []string{"error"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeSARIF(&buf, tt.entries); err != nil {
+				t.Fatal(err)
+			}
+			var log sarifLog
+			if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+				t.Fatal(err)
+			}
+			run := log.Runs[0]
+			if len(run.Results) != tt.results {
+				t.Errorf("got %d results, want %d", len(run.Results), tt.results)
+			}
+			if len(run.Invocations) != 1 {
+				t.Fatalf("got %d invocations, want 1", len(run.Invocations))
+			}
+			invocation := run.Invocations[0]
+			if invocation.ExecutionSuccessful != tt.successful {
+				t.Errorf("executionSuccessful = %v, want %v", invocation.ExecutionSuccessful, tt.successful)
+			}
+			var levels []string
+			for _, n := range invocation.ToolExecutionNotifications {
+				levels = append(levels, n.Level)
+			}
+			if len(levels) != len(tt.notifications) {
+				t.Fatalf("notification levels = %v, want %v", levels, tt.notifications)
+			}
+			for i := range levels {
+				if levels[i] != tt.notifications[i] {
+					t.Errorf("notification levels = %v, want %v", levels, tt.notifications)
+				}
+			}
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := writeSARIF(&buf, []Entry{fetchError}); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	n := log.Runs[0].Invocations[0].ToolExecutionNotifications[0]
+	if n.Message.Text != "node:18: failed to fetch EOL data: timeout" {
+		t.Errorf("message = %q", n.Message.Text)
+	}
+	if len(n.Locations) != 1 || n.Locations[0].PhysicalLocation.ArtifactLocation.URI != "compose.yaml" || n.Locations[0].PhysicalLocation.Region.StartLine != 4 {
+		t.Errorf("locations = %+v, want compose.yaml:4", n.Locations)
+	}
+}
@@ -0,0 +1,112 @@
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// writeText writes a plain-text rendering of each entry
+func writeText(w io.Writer, entries []Entry) error {
+	bw := bufio.NewWriter(w)
+	for i, entry := range entries {
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		writeTextEntry(bw, entry)
+	}
+	return bw.Flush()
+}
+
+func writeTextEntry(w io.Writer, entry Entry) {
+	subject := entry.Name()
+	if entry.Location != nil {
+		subject = textLocation(entry.Location)
+		if entry.Name() != "" {
+			subject += ": " + entry.Name()
+		}
+	}
+
+	if entry.Error != "" {
+		fmt.Fprintf(w, "%s: error: %s\n", subject, entry.Error)
+		return
+	}
+
+	result := entry.Result
+	fmt.Fprintf(w, "%s: %s\n", subject, result.Status)
+
+	if entry.Raw != "" {
+		fmt.Fprintf(w, "  Written as: %s\n", entry.Raw)
+	}
+
+	keys := make([]string, 0, len(entry.Context))
+	for key := range entry.Context {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "  %s: %s\n", strings.ToUpper(key[:1])+key[1:], entry.Context[key])
+	}
+
+	fmt.Fprintf(w, "  Product: %s", result.Product)
+	if result.Version != "" {
+		fmt.Fprintf(w, " (version: %s)", result.Version)
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "  %s\n", result.Description)
+
+	if result.EOLDate != "" && result.EOLDate != "false" {
+		fmt.Fprintf(w, "  EOL Date: %s", result.EOLDate)
+		if result.DaysRemaining >= 0 {
+			fmt.Fprintf(w, " (%d days remaining)", result.DaysRemaining)
+		}
+		fmt.Fprintln(w)
+	}
+
+	if result.SupportEndDate != "" && result.SupportEndDate != "false" {
+		fmt.Fprintf(w, "  Support End: %s\n", result.SupportEndDate)
+	}
+
+	if entry.Blame != nil {
+		fmt.Fprintf(w, "  Introduced: %s\n", blameText(entry.Blame))
+	}
+
+	if result.Latest != "" {
+		fmt.Fprintf(w, "  Latest Version: %s\n", result.Latest)
+	}
+
+	if result.Recommendation != "" {
+		fmt.Fprintf(w, "  Recommendation: %s\n", result.Recommendation)
+	}
+
+	if result.Link != "" {
+		fmt.Fprintf(w, "  More Info: %s\n", result.Link)
+	}
+}
+
+func textLocation(loc *Location) string {
+	if loc.Line > 0 {
+		return fmt.Sprintf("%s:%d", loc.File, loc.Line)
+	}
+	return loc.File
+}
+
+// blameText describes the commit that introduced a reference
+func blameText(blame *Blame) string {
+	commit := blame.Commit
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	text := fmt.Sprintf("%s by %s on %s", commit, blame.Author, blame.Date)
+	switch {
+	case blame.DaysPastEOL == nil:
+	case *blame.DaysPastEOL >= 0:
+		text += fmt.Sprintf(" (%d days after EOL)", *blame.DaysPastEOL)
+	default:
+		text += fmt.Sprintf(" (%d days before EOL)", -*blame.DaysPastEOL)
+	}
+	return text
+}
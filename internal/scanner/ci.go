@@ -0,0 +1,339 @@
+package scanner
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// runnerLabel matches versioned GitHub-hosted runner labels such as
+	// ubuntu-20.04, windows-2019 or macos-13-xlarge
+	runnerLabel = regexp.MustCompile(`^(ubuntu|windows|macos)-(\d+(?:\.\d+)?)(?:-[\w-]+)?$`)
+	// matrixExpression matches ${{ matrix.<key> }}
+	matrixExpression = regexp.MustCompile(`\$\{\{\s*matrix\.([\w-]+)\s*\}\}`)
+	// workflowExpression matches any ${{ ... }} expression
+	workflowExpression = regexp.MustCompile(`\$\{\{\s*(.*?)\s*\}\}`)
+)
+
+// runnerProducts maps runner label prefixes to endoflife.date products
+var runnerProducts = map[string]string{
+	"ubuntu":  "ubuntu",
+	"windows": "windows-server",
+	"macos":   "macos",
+}
+
+// GitHubActionsScanner finds job containers, service containers, docker://
+// steps and versioned runner labels in GitHub Actions workflows
+type GitHubActionsScanner struct{}
+
+// NewGitHubActionsScanner creates a GitHub Actions workflow scanner
+func NewGitHubActionsScanner() *GitHubActionsScanner {
+	return &GitHubActionsScanner{}
+}
+
+// Name identifies the scanner
+func (s *GitHubActionsScanner) Name() string {
+	return "github-actions"
+}
+
+// Match recognizes YAML files under .github/workflows
+func (s *GitHubActionsScanner) Match(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	dir := filepath.ToSlash(filepath.Dir(path))
+	return (ext == ".yml" || ext == ".yaml") &&
+		(strings.HasSuffix(dir, ".github/workflows") || strings.Contains(dir, ".github/workflows/"))
+}
+
+// Scan returns the images and runner OS versions used by every job.
+// ${{ matrix.<key> }} expressions are expanded over the job's matrix.
+func (s *GitHubActionsScanner) Scan(path string) ([]Reference, error) {
+	docs, err := readYAML(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Reference
+	for _, doc := range docs {
+		for _, job := range mappingEntries(mappingValue(doc, "jobs")) {
+			w := &workflowJob{
+				path:   path,
+				job:    job.key.Value,
+				matrix: mappingValue(mappingValue(job.value, "strategy"), "matrix"),
+			}
+			refs = append(refs, w.scan(job.value)...)
+		}
+	}
+	return refs, nil
+}
+
+// workflowJob collects the references of one workflow job
+type workflowJob struct {
+	path   string
+	job    string
+	matrix *yaml.Node
+	refs   []Reference
+}
+
+func (w *workflowJob) scan(job *yaml.Node) []Reference {
+	runsOn := mappingValue(job, "runs-on")
+	if labels := mappingValue(runsOn, "labels"); labels != nil {
+		runsOn = labels
+	}
+	if _, ok := scalarValue(runsOn); ok {
+		w.addRunner(runsOn)
+	}
+	for _, label := range sequenceItems(runsOn) {
+		w.addRunner(label)
+	}
+
+	container := mappingValue(job, "container")
+	if _, ok := scalarValue(container); !ok {
+		container = mappingValue(container, "image")
+	}
+	w.addImage(container, map[string]string{"container": "job"})
+
+	for _, svc := range mappingEntries(mappingValue(job, "services")) {
+		w.addImage(mappingValue(svc.value, "image"), map[string]string{"service": svc.key.Value})
+	}
+
+	for i, step := range sequenceItems(mappingValue(job, "steps")) {
+		uses, node := mappingString(step, "uses")
+		if !strings.HasPrefix(uses, "docker://") {
+			continue
+		}
+		stepName, _ := mappingString(step, "name")
+		if stepName == "" {
+			stepName = "#" + strconv.Itoa(i+1)
+		}
+		imageNode := *node
+		imageNode.Value = strings.TrimPrefix(uses, "docker://")
+		w.addImage(&imageNode, map[string]string{"step": stepName})
+	}
+
+	return w.refs
+}
+
+// addRunner adds a product reference for a versioned runner label
+func (w *workflowJob) addRunner(node *yaml.Node) {
+	raw, ok := scalarValue(node)
+	if !ok {
+		return
+	}
+	for _, value := range w.expandMatrix(raw) {
+		label := strings.ToLower(value.text)
+		m := runnerLabel.FindStringSubmatch(label)
+		if m == nil {
+			continue
+		}
+		ref := Reference{
+			Product: runnerProducts[m[1]],
+			Version: m[2],
+			Subject: "runner " + label,
+			File:    w.path,
+			Line:    node.Line,
+			Context: w.context(map[string]string{"runs-on": label}, value.matrix),
+		}
+		w.refs = append(w.refs, ref)
+	}
+}
+
+// addImage adds an image reference for a scalar node
+func (w *workflowJob) addImage(node *yaml.Node, context map[string]string) {
+	raw, ok := scalarValue(node)
+	if !ok || raw == "" {
+		return
+	}
+	for _, value := range w.expandMatrix(raw) {
+		ref := Reference{
+			Image:   value.text,
+			File:    w.path,
+			Line:    node.Line,
+			Context: w.context(context, value.matrix),
+		}
+		if value.text != raw {
+			ref.Raw = raw
+		}
+		if m := workflowExpression.FindAllStringSubmatch(value.text, -1); m != nil {
+			ref.Image, ref.Raw = raw, ""
+			for _, expr := range m {
+				ref.Unresolved = append(ref.Unresolved, expr[1])
+			}
+		}
+		w.refs = append(w.refs, ref)
+	}
+}
+
+func (w *workflowJob) context(extra map[string]string, matrix string) map[string]string {
+	context := map[string]string{"job": w.job}
+	for k, v := range extra {
+		context[k] = v
+	}
+	if matrix != "" {
+		context["matrix"] = matrix
+	}
+	return context
+}
+
+// matrixValue is a string with its matrix expressions substituted
+type matrixValue struct {
+	text   string
+	matrix string
+}
+
+// expandMatrix substitutes a single ${{ matrix.<key> }} expression with
+// each value the matrix gives the key, including include entries. Strings
+// without one, or with several different keys, are returned unchanged.
+func (w *workflowJob) expandMatrix(raw string) []matrixValue {
+	matches := matrixExpression.FindAllStringSubmatch(raw, -1)
+	if len(matches) == 0 {
+		return []matrixValue{{text: raw}}
+	}
+	key := matches[0][1]
+	for _, m := range matches[1:] {
+		if m[1] != key {
+			return []matrixValue{{text: raw}}
+		}
+	}
+
+	var values []string
+	for _, item := range sequenceItems(mappingValue(w.matrix, key)) {
+		if v, ok := scalarValue(item); ok {
+			values = append(values, v)
+		}
+	}
+	for _, include := range sequenceItems(mappingValue(w.matrix, "include")) {
+		if v, node := mappingString(include, key); node != nil {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return []matrixValue{{text: raw}}
+	}
+
+	seen := make(map[string]bool, len(values))
+	var expanded []matrixValue
+	for _, v := range values {
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		expanded = append(expanded, matrixValue{
+			text:   matrixExpression.ReplaceAllLiteralString(raw, v),
+			matrix: key + "=" + v,
+		})
+	}
+	return expanded
+}
+
+// gitlabKeywords are the top-level keys of .gitlab-ci.yml that are not jobs
+var gitlabKeywords = map[string]bool{
+	"default": true, "include": true, "stages": true, "variables": true,
+	"workflow": true, "image": true, "services": true, "cache": true,
+	"before_script": true, "after_script": true, "spec": true,
+}
+
+// GitLabCIScanner finds the images and services of GitLab CI jobs
+type GitLabCIScanner struct{}
+
+// NewGitLabCIScanner creates a GitLab CI scanner
+func NewGitLabCIScanner() *GitLabCIScanner {
+	return &GitLabCIScanner{}
+}
+
+// Name identifies the scanner
+func (s *GitLabCIScanner) Name() string {
+	return "gitlab-ci"
+}
+
+// Match recognizes .gitlab-ci.yml and included *.gitlab-ci.yml files
+func (s *GitLabCIScanner) Match(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	return strings.HasSuffix(name, ".gitlab-ci.yml") || strings.HasSuffix(name, ".gitlab-ci.yaml")
+}
+
+// Scan returns the images of the default section and of every job, with
+// GitLab CI variables substituted. Predefined CI_* variables are unknown
+// outside a pipeline and leave the image unresolved.
+func (s *GitLabCIScanner) Scan(path string) ([]Reference, error) {
+	docs, err := readYAML(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Reference
+	for _, doc := range docs {
+		globals := gitlabVariables(mappingValue(doc, "variables"))
+		lookup := func(name string) (string, bool) {
+			value, ok := globals[name]
+			return value, ok
+		}
+
+		refs = append(refs, gitlabImages(doc, path, "(global)", lookup)...)
+		refs = append(refs, gitlabImages(mappingValue(doc, "default"), path, "(default)", lookup)...)
+
+		for _, job := range mappingEntries(doc) {
+			if gitlabKeywords[job.key.Value] || job.value.Kind != yaml.MappingNode {
+				continue
+			}
+			jobVars := gitlabVariables(mappingValue(job.value, "variables"))
+			jobLookup := func(name string) (string, bool) {
+				if value, ok := jobVars[name]; ok {
+					return value, true
+				}
+				return lookup(name)
+			}
+			refs = append(refs, gitlabImages(job.value, path, job.key.Value, jobLookup)...)
+		}
+	}
+	return refs, nil
+}
+
+// gitlabImages returns the image and services of a job or default section
+func gitlabImages(section *yaml.Node, path, job string, lookup func(string) (string, bool)) []Reference {
+	var refs []Reference
+	add := func(node *yaml.Node, kind string) {
+		if _, ok := scalarValue(node); !ok {
+			node = mappingValue(node, "name")
+		}
+		raw, ok := scalarValue(node)
+		if !ok || raw == "" {
+			return
+		}
+		x := &expander{lookup: lookup, dollarEscape: true}
+		context := map[string]string{"job": job}
+		if kind != "" {
+			context["service"] = kind
+		}
+		refs = append(refs, newReference(raw, x, path, node.Line, context))
+	}
+
+	add(mappingValue(section, "image"), "")
+	for _, svc := range sequenceItems(mappingValue(section, "services")) {
+		name, _ := scalarValue(svc)
+		if alias, _ := mappingString(svc, "alias"); alias != "" {
+			name = alias
+		} else if name == "" {
+			name, _ = mappingString(svc, "name")
+		}
+		add(svc, name)
+	}
+	return refs
+}
+
+// gitlabVariables reads a variables section, whose values are either
+// scalars or mappings with a value key
+func gitlabVariables(n *yaml.Node) map[string]string {
+	vars := make(map[string]string)
+	for _, e := range mappingEntries(n) {
+		if value, ok := scalarValue(e.value); ok {
+			vars[e.key.Value] = value
+		} else if value, node := mappingString(e.value, "value"); node != nil {
+			vars[e.key.Value] = value
+		}
+	}
+	return vars
+}
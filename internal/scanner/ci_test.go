@@ -0,0 +1,205 @@
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGitHubActionsScan(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow string
+		want     []Reference
+	}{
+		{
+			name: "runner, container, services and docker steps",
+			workflow: `on: push
+jobs:
+  test:
+    runs-on: ubuntu-20.04
+    container:
+      image: node:16
+    services:
+      db:
+        image: postgres:11
+    steps:
+      - uses: actions/checkout@v4
+      - name: lint
+        uses: docker://hadolint/hadolint:2.12.0
+`,
+			want: []Reference{
+				{Product: "ubuntu", Version: "20.04", Subject: "runner ubuntu-20.04", Line: 4, Context: map[string]string{"job": "test", "runs-on": "ubuntu-20.04"}},
+				{Image: "node:16", Line: 6, Context: map[string]string{"job": "test", "container": "job"}},
+				{Image: "postgres:11", Line: 9, Context: map[string]string{"job": "test", "service": "db"}},
+				{Image: "hadolint/hadolint:2.12.0", Line: 13, Context: map[string]string{"job": "test", "step": "lint"}},
+			},
+		},
+		{
+			name: "unversioned and self-hosted runners",
+			workflow: `jobs:
+  build:
+    runs-on: [self-hosted, ubuntu-latest]
+  mac:
+    runs-on:
+      labels: macos-13-xlarge
+  win:
+    runs-on: windows-2019
+    container: mcr.microsoft.com/windows/servercore:ltsc2019
+`,
+			want: []Reference{
+				{Product: "macos", Version: "13", Subject: "runner macos-13-xlarge", Line: 6, Context: map[string]string{"job": "mac", "runs-on": "macos-13-xlarge"}},
+				{Product: "windows-server", Version: "2019", Subject: "runner windows-2019", Line: 8, Context: map[string]string{"job": "win", "runs-on": "windows-2019"}},
+				{Image: "mcr.microsoft.com/windows/servercore:ltsc2019", Line: 9, Context: map[string]string{"job": "win", "container": "job"}},
+			},
+		},
+		{
+			name: "matrix",
+			workflow: `jobs:
+  test:
+    strategy:
+      matrix:
+        os: [ubuntu-22.04]
+        python: ["3.8", "3.12"]
+        include:
+          - python: "3.7"
+    runs-on: ${{ matrix.os }}
+    container: python:${{ matrix.python }}
+`,
+			want: []Reference{
+				{Product: "ubuntu", Version: "22.04", Subject: "runner ubuntu-22.04", Line: 9, Context: map[string]string{"job": "test", "runs-on": "ubuntu-22.04", "matrix": "os=ubuntu-22.04"}},
+				{Image: "python:3.8", Raw: "python:${{ matrix.python }}", Line: 10, Context: map[string]string{"job": "test", "container": "job", "matrix": "python=3.8"}},
+				{Image: "python:3.12", Raw: "python:${{ matrix.python }}", Line: 10, Context: map[string]string{"job": "test", "container": "job", "matrix": "python=3.12"}},
+				{Image: "python:3.7", Raw: "python:${{ matrix.python }}", Line: 10, Context: map[string]string{"job": "test", "container": "job", "matrix": "python=3.7"}},
+			},
+		},
+		{
+			name: "unresolved expression",
+			workflow: `jobs:
+  test:
+    runs-on: ubuntu-24.04
+    container: node:${{ inputs.node }}
+`,
+			want: []Reference{
+				{Product: "ubuntu", Version: "24.04", Subject: "runner ubuntu-24.04", Line: 3, Context: map[string]string{"job": "test", "runs-on": "ubuntu-24.04"}},
+				{Image: "node:${{ inputs.node }}", Line: 4, Context: map[string]string{"job": "test", "container": "job"}, Unresolved: []string{"inputs.node"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".github", "workflows", "ci.yml")
+			writeTestFile(t, path, tt.workflow)
+
+			got, err := NewGitHubActionsScanner().Scan(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range tt.want {
+				tt.want[i].File = path
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitLabCIScan(t *testing.T) {
+	tests := []struct {
+		name string
+		ci   string
+		want []Reference
+	}{
+		{
+			name: "global, default and job images",
+			ci: `image: alpine:3.12
+default:
+  image: ruby:2.7
+  services:
+    - postgres:12
+stages: [test]
+test:
+  stage: test
+  image:
+    name: node:18
+  services:
+    - name: redis:6
+      alias: cache
+`,
+			want: []Reference{
+				{Image: "alpine:3.12", Line: 1, Context: map[string]string{"job": "(global)"}},
+				{Image: "ruby:2.7", Line: 3, Context: map[string]string{"job": "(default)"}},
+				{Image: "postgres:12", Line: 5, Context: map[string]string{"job": "(default)", "service": "postgres:12"}},
+				{Image: "node:18", Line: 10, Context: map[string]string{"job": "test"}},
+				{Image: "redis:6", Line: 12, Context: map[string]string{"job": "test", "service": "cache"}},
+			},
+		},
+		{
+			name: "variables",
+			ci: `variables:
+  PYTHON: "3.8"
+  NODE:
+    value: "20"
+lint:
+  image: python:$PYTHON
+build:
+  variables:
+    PYTHON: "3.12"
+  image: python:${PYTHON}
+web:
+  image: node:$NODE
+deploy:
+  image: $CI_REGISTRY_IMAGE:latest
+`,
+			want: []Reference{
+				{Image: "python:3.8", Raw: "python:$PYTHON", Line: 6, Context: map[string]string{"job": "lint"}},
+				{Image: "python:3.12", Raw: "python:${PYTHON}", Line: 10, Context: map[string]string{"job": "build"}},
+				{Image: "node:20", Raw: "node:$NODE", Line: 12, Context: map[string]string{"job": "web"}},
+				{Image: "$CI_REGISTRY_IMAGE:latest", Line: 14, Context: map[string]string{"job": "deploy"}, Unresolved: []string{"CI_REGISTRY_IMAGE"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".gitlab-ci.yml")
+			writeTestFile(t, path, tt.ci)
+
+			got, err := NewGitLabCIScanner().Scan(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range tt.want {
+				tt.want[i].File = path
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCIMatch(t *testing.T) {
+	github := NewGitHubActionsScanner()
+	gitlab := NewGitLabCIScanner()
+	tests := []struct {
+		path           string
+		github, gitlab bool
+	}{
+		{".github/workflows/ci.yml", true, false},
+		{"repo/.github/workflows/release.yaml", true, false},
+		{".github/workflows/README.md", false, false},
+		{".github/dependabot.yml", false, false},
+		{".gitlab-ci.yml", false, true},
+		{"ci/templates/test.gitlab-ci.yml", false, true},
+		{"gitlab-ci.yml", false, false},
+	}
+	for _, tt := range tests {
+		if got := github.Match(tt.path); got != tt.github {
+			t.Errorf("GitHubActionsScanner.Match(%q) = %v, want %v", tt.path, got, tt.github)
+		}
+		if got := gitlab.Match(tt.path); got != tt.gitlab {
+			t.Errorf("GitLabCIScanner.Match(%q) = %v, want %v", tt.path, got, tt.gitlab)
+		}
+	}
+}
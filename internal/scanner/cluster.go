@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// kubernetesProduct is the endoflife.date product of upstream Kubernetes
+const kubernetesProduct = "kubernetes"
+
+// scanClusterConfig returns the Kubernetes versions of a kind cluster or
+// kubeadm configuration. ok is false when obj is neither.
+func scanClusterConfig(obj *yaml.Node, kind, path string) (refs []Reference, ok bool) {
+	apiVersion, _ := mappingString(obj, "apiVersion")
+	switch {
+	case kind == "Cluster" && strings.HasPrefix(apiVersion, "kind.x-k8s.io/"):
+		return kindNodeVersions(obj, path), true
+	case kind == "ClusterConfiguration" && strings.HasPrefix(apiVersion, "kubeadm.k8s.io/"):
+		return kubeadmVersion(obj, path), true
+	}
+	return nil, false
+}
+
+// kindNodeVersions reads the Kubernetes version from the kindest/node image
+// of each node. Nodes without an image use the release's default.
+func kindNodeVersions(obj *yaml.Node, path string) []Reference {
+	var refs []Reference
+	for i, node := range sequenceItems(mappingValue(obj, "nodes")) {
+		imageName, imageNode := mappingString(node, "image")
+		if imageNode == nil {
+			continue
+		}
+		role, _ := mappingString(node, "role")
+		if role == "" {
+			role = "control-plane"
+		}
+
+		// The tag is the Kubernetes version, e.g. kindest/node:v1.27.3@sha256:...
+		withoutDigest, _, _ := strings.Cut(imageName, "@")
+		_, tag, _ := strings.Cut(withoutDigest[strings.LastIndex(withoutDigest, "/")+1:], ":")
+		match := leadingVersion.FindStringSubmatch(tag)
+		if match == nil {
+			continue
+		}
+		refs = append(refs, Reference{
+			Product: kubernetesProduct,
+			Version: match[1],
+			Subject: "kind node image " + withoutDigest,
+			File:    path,
+			Line:    imageNode.Line,
+			Context: map[string]string{"kind": "Cluster", "node": "nodes[" + strconv.Itoa(i) + "] (" + role + ")"},
+		})
+	}
+	return refs
+}
+
+// kubeadmVersion reads kubernetesVersion, written as v1.27.3 or as a
+// stable-1.27 release label
+func kubeadmVersion(obj *yaml.Node, path string) []Reference {
+	version, node := mappingString(obj, "kubernetesVersion")
+	if node == nil {
+		return nil
+	}
+	match := leadingVersion.FindStringSubmatch(strings.TrimPrefix(version, "stable-"))
+	if match == nil {
+		return nil
+	}
+	context := map[string]string{"kind": "ClusterConfiguration"}
+	if name, _ := mappingString(obj, "clusterName"); name != "" {
+		context["name"] = name
+	}
+	return []Reference{{
+		Product: kubernetesProduct,
+		Version: match[1],
+		Subject: "kubeadm kubernetesVersion " + version,
+		File:    path,
+		Line:    node.Line,
+		Context: context,
+	}}
+}
@@ -0,0 +1,216 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var composeFileName = regexp.MustCompile(`^(docker-)?compose(\.[\w.-]+)?\.ya?ml$`)
+
+// ComposeScanner finds the images of docker-compose services, following
+// build sections into their Dockerfiles
+type ComposeScanner struct {
+	dockerfiles *DockerfileScanner
+}
+
+// NewComposeScanner creates a compose scanner that reads build Dockerfiles
+// with the given Dockerfile scanner
+func NewComposeScanner(dockerfiles *DockerfileScanner) *ComposeScanner {
+	return &ComposeScanner{dockerfiles: dockerfiles}
+}
+
+// Name identifies the scanner
+func (s *ComposeScanner) Name() string {
+	return "compose"
+}
+
+// Match recognizes compose.yaml, docker-compose.yml and their override files
+func (s *ComposeScanner) Match(path string) bool {
+	return composeFileName.MatchString(strings.ToLower(filepath.Base(path)))
+}
+
+// Scan returns one reference per service image. Services with a build
+// section report the base images of their Dockerfile instead, since their
+// image key only names the build output, unless the Dockerfile can't be read.
+func (s *ComposeScanner) Scan(path string) ([]Reference, error) {
+	docs, err := readYAML(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(path)
+	envPath := filepath.Join(dir, ".env")
+	env, err := readEnvFile(envPath)
+	if err != nil {
+		return nil, err
+	}
+	// usesEnv tells whether a service looked up a variable that the
+	// environment doesn't set, which makes the .env file one of its sources
+	usesEnv := false
+	lookup := func(name string) (string, bool) {
+		if value, ok := os.LookupEnv(name); ok {
+			return value, true
+		}
+		usesEnv = true
+		value, ok := env[name]
+		return value, ok
+	}
+
+	var refs []Reference
+	for _, doc := range docs {
+		for _, svc := range mappingEntries(mappingValue(doc, "services")) {
+			usesEnv = false
+			serviceRefs, err := s.scanService(dir, svc, lookup, path)
+			if err != nil {
+				return nil, err
+			}
+			if usesEnv && FileExists(envPath) {
+				addSources(serviceRefs, envPath)
+			}
+			refs = append(refs, serviceRefs...)
+		}
+	}
+	return refs, nil
+}
+
+// scanService returns the images of a service, through its Dockerfile when
+// it has a build section
+func (s *ComposeScanner) scanService(dir string, svc yamlEntry, lookup func(string) (string, bool), path string) ([]Reference, error) {
+	context := map[string]string{"service": svc.key.Value}
+	if build := mappingValue(svc.value, "build"); build != nil {
+		refs, ok, err := s.scanBuild(dir, build, lookup, path, context)
+		if err != nil || ok {
+			return refs, err
+		}
+	}
+
+	raw, node := mappingString(svc.value, "image")
+	if node == nil {
+		return nil, nil
+	}
+	x := &expander{lookup: lookup, dollarEscape: true}
+	return []Reference{newReference(raw, x, path, node.Line, context)}, nil
+}
+
+// scanBuild scans the Dockerfile of a service build section, which is
+// either a context path or a mapping with context, dockerfile and args. It
+// reports false when there is no local Dockerfile to read.
+func (s *ComposeScanner) scanBuild(dir string, build *yaml.Node, lookup func(string) (string, bool), composePath string, context map[string]string) ([]Reference, bool, error) {
+	x := &expander{lookup: lookup, dollarEscape: true}
+
+	buildContext, dockerfile := ".", "Dockerfile"
+	args := make(map[string]string)
+	if value, ok := scalarValue(build); ok {
+		buildContext = x.expand(value)
+	} else {
+		if value, node := mappingString(build, "context"); node != nil {
+			buildContext = x.expand(value)
+		}
+		if value, node := mappingString(build, "dockerfile"); node != nil {
+			dockerfile = x.expand(value)
+		}
+		if _, node := mappingString(build, "dockerfile_inline"); node != nil {
+			return nil, false, nil
+		}
+		composeBuildArgs(mappingValue(build, "args"), x, args)
+	}
+
+	// Remote contexts such as git repositories can't be read locally
+	if strings.Contains(buildContext, "://") || strings.HasPrefix(buildContext, "git@") || len(x.unresolved) > 0 {
+		return nil, false, nil
+	}
+
+	if !filepath.IsAbs(buildContext) {
+		buildContext = filepath.Join(dir, buildContext)
+	}
+	if !filepath.IsAbs(dockerfile) {
+		dockerfile = filepath.Join(buildContext, dockerfile)
+	}
+
+	refs, err := s.dockerfiles.ScanWithArgs(dockerfile, args)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	for i := range refs {
+		for k, v := range context {
+			refs[i].Context[k] = v
+		}
+		// Dockerfile references point into another file, so record
+		// which compose file led there
+		refs[i].Context["compose"] = composePath
+	}
+	addSources(refs, composePath)
+	return refs, true, nil
+}
+
+// composeBuildArgs reads build args given as a mapping or as a list of
+// KEY=VALUE strings. A KEY without value is taken from the environment.
+func composeBuildArgs(n *yaml.Node, x *expander, args map[string]string) {
+	for _, e := range mappingEntries(n) {
+		if value, ok := scalarValue(e.value); ok {
+			args[e.key.Value] = x.expand(value)
+		} else if value, ok := x.lookup(e.key.Value); ok {
+			args[e.key.Value] = value
+		}
+	}
+	for _, item := range sequenceItems(n) {
+		value, ok := scalarValue(item)
+		if !ok {
+			continue
+		}
+		key, val, hasValue := strings.Cut(x.expand(value), "=")
+		if !hasValue {
+			if val, hasValue = x.lookup(key); !hasValue {
+				continue
+			}
+		}
+		args[key] = val
+	}
+}
+
+// readEnvFile reads KEY=VALUE lines from a .env file. A missing file is
+// treated as empty.
+func readEnvFile(path string) (map[string]string, error) {
+	env := make(map[string]string)
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return env, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) > 0 && (value[0] == '"' || value[0] == '\'') {
+			// Quoted values end at the closing quote
+			if i := strings.IndexByte(value[1:], value[0]); i >= 0 {
+				value = value[1 : i+1]
+			}
+		} else if i := strings.Index(value, " #"); i >= 0 {
+			// Unquoted values end at an inline comment
+			value = strings.TrimSpace(value[:i])
+		}
+		env[strings.TrimSpace(key)] = value
+	}
+	return env, sc.Err()
+}
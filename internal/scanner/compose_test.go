@@ -0,0 +1,136 @@
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestComposeScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		compose string
+		files   map[string]string
+		want    []string
+	}{
+		{
+			name: "images",
+			compose: `services:
+  web:
+    image: nginx:1.25
+  db:
+    image: postgres:15
+`,
+			want: []string{"nginx:1.25", "postgres:15"},
+		},
+		{
+			name: "env file",
+			compose: `services:
+  db:
+    image: postgres:${PG_VERSION:-13}
+  cache:
+    image: redis:${REDIS_VERSION}
+  escaped:
+    image: busybox:$$TAG
+`,
+			files: map[string]string{".env": "# versions\nPG_VERSION=16\nexport REDIS_VERSION='7.2' # pinned\n"},
+			want:  []string{"postgres:16", "redis:7.2", "busybox:$TAG"},
+		},
+		{
+			name: "build with args",
+			compose: `services:
+  app:
+    image: example/app:latest
+    build:
+      context: ./app
+      dockerfile: Dockerfile.prod
+      args:
+        - NODE=20
+`,
+			files: map[string]string{"app/Dockerfile.prod": "ARG NODE=18\nFROM node:${NODE}-alpine\n"},
+			want:  []string{"node:20-alpine"},
+		},
+		{
+			name: "missing Dockerfile falls back to image",
+			compose: `services:
+  app:
+    image: python:3.12
+    build: ./missing
+`,
+			want: []string{"python:3.12"},
+		},
+		{
+			name: "remote context falls back to image",
+			compose: `services:
+  app:
+    image: node:20
+    build: https://github.com/example/app.git
+  inline:
+    build:
+      dockerfile_inline: FROM alpine:3.19
+`,
+			want: []string{"node:20"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeTestFile(t, filepath.Join(dir, name), content)
+			}
+			path := filepath.Join(dir, "compose.yaml")
+			writeTestFile(t, path, tt.compose)
+
+			refs, err := NewComposeScanner(NewDockerfileScanner(nil)).Scan(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := referenceImages(refs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComposeBuildContext(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "docker-compose.yml")
+	writeTestFile(t, path, `services:
+  api:
+    build:
+      context: .
+      args:
+        GO: ${GO_VERSION}
+  web:
+    image: nginx:1.25
+`)
+	dockerfile := filepath.Join(dir, "Dockerfile")
+	writeTestFile(t, dockerfile, "ARG GO=1.21\nFROM golang:${GO} AS build\n")
+	env := filepath.Join(dir, ".env")
+	writeTestFile(t, env, "GO_VERSION=1.22\n")
+
+	refs, err := NewComposeScanner(NewDockerfileScanner(nil)).Scan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Only the service that reads a variable from .env has it as a source
+	want := []Reference{
+		{
+			Image:   "golang:1.22",
+			Raw:     "golang:${GO}",
+			File:    dockerfile,
+			Line:    2,
+			Context: map[string]string{"stage": "build", "service": "api", "compose": path},
+			Sources: []string{path, env},
+		},
+		{
+			Image:   "nginx:1.25",
+			File:    path,
+			Line:    8,
+			Context: map[string]string{"service": "web"},
+		},
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("Scan() = %+v, want %+v", refs, want)
+	}
+}
@@ -0,0 +1,262 @@
+package scanner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// devcontainerFeatureProducts maps the ids of well-known Dev Container
+// features to the endoflife.date product their version option selects
+var devcontainerFeatureProducts = map[string]string{
+	"node":                     "nodejs",
+	"python":                   "python",
+	"go":                       "go",
+	"ruby":                     "ruby",
+	"php":                      "php",
+	"dotnet":                   "dotnet",
+	"terraform":                "terraform",
+	"powershell":               "powershell",
+	"docker-in-docker":         "docker-engine",
+	"docker-outside-of-docker": "docker-engine",
+	"kubectl-helm-minikube":    "kubernetes",
+}
+
+// DevcontainerScanner finds the image, Dockerfile, compose files and
+// versioned features of Dev Container configurations
+type DevcontainerScanner struct {
+	dockerfiles *DockerfileScanner
+	compose     *ComposeScanner
+}
+
+// NewDevcontainerScanner creates a Dev Container scanner that follows
+// Dockerfiles and compose files with the given scanners
+func NewDevcontainerScanner(dockerfiles *DockerfileScanner, compose *ComposeScanner) *DevcontainerScanner {
+	return &DevcontainerScanner{dockerfiles: dockerfiles, compose: compose}
+}
+
+// Name identifies the scanner
+func (s *DevcontainerScanner) Name() string {
+	return "devcontainer"
+}
+
+// Match recognizes devcontainer.json and .devcontainer.json
+func (s *DevcontainerScanner) Match(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	return name == "devcontainer.json" || name == ".devcontainer.json"
+}
+
+// Scan returns the container image of the configuration, or the base
+// images of its Dockerfile or compose services, plus one product reference
+// per feature pinned to a version
+func (s *DevcontainerScanner) Scan(path string) ([]Reference, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := parseYAML(stripJSONC(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	config := docs[0]
+	dir := filepath.Dir(path)
+
+	var refs []Reference
+	if imageName, node := mappingString(config, "image"); node != nil {
+		refs = append(refs, Reference{
+			Image:   imageName,
+			File:    path,
+			Line:    node.Line,
+			Context: map[string]string{"devcontainer": "image"},
+		})
+	}
+
+	buildRefs, err := s.scanBuild(config, dir)
+	if err != nil {
+		return nil, err
+	}
+	addSources(buildRefs, path)
+	refs = append(refs, buildRefs...)
+
+	composeRefs, err := s.scanCompose(config, dir)
+	if err != nil {
+		return nil, err
+	}
+	addSources(composeRefs, path)
+	refs = append(refs, composeRefs...)
+
+	for _, feature := range mappingEntries(mappingValue(config, "features")) {
+		if ref, ok := featureReference(feature, path); ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// scanBuild follows build.dockerfile, or the legacy dockerFile property,
+// into the Dockerfile scanner with the configured build args
+func (s *DevcontainerScanner) scanBuild(config *yaml.Node, dir string) ([]Reference, error) {
+	build := mappingValue(config, "build")
+	dockerfile, node := mappingString(build, "dockerfile")
+	if node == nil {
+		dockerfile, node = mappingString(config, "dockerFile")
+	}
+	if node == nil {
+		return nil, nil
+	}
+
+	args := make(map[string]string)
+	for _, e := range mappingEntries(mappingValue(build, "args")) {
+		if value, ok := scalarValue(e.value); ok {
+			args[e.key.Value] = value
+		}
+	}
+
+	refs, err := s.dockerfiles.ScanWithArgs(filepath.Join(dir, dockerfile), args)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for i := range refs {
+		refs[i].Context["devcontainer"] = "build"
+	}
+	return refs, nil
+}
+
+// scanCompose scans the compose files of a compose-based configuration
+func (s *DevcontainerScanner) scanCompose(config *yaml.Node, dir string) ([]Reference, error) {
+	composeFiles := mappingValue(config, "dockerComposeFile")
+	files := sequenceItems(composeFiles)
+	if _, ok := scalarValue(composeFiles); ok {
+		files = []*yaml.Node{composeFiles}
+	}
+
+	var refs []Reference
+	for _, file := range files {
+		name, ok := scalarValue(file)
+		if !ok {
+			continue
+		}
+		composeRefs, err := s.compose.Scan(filepath.Join(dir, name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		for i := range composeRefs {
+			composeRefs[i].Context["devcontainer"] = "compose"
+		}
+		refs = append(refs, composeRefs...)
+	}
+	return refs, nil
+}
+
+// featureReference builds a product reference for a feature such as
+// "ghcr.io/devcontainers/features/node:1": {"version": "18"}
+func featureReference(feature yamlEntry, path string) (Reference, bool) {
+	id := feature.key.Value
+	id = id[strings.LastIndex(id, "/")+1:]
+	if i := strings.IndexAny(id, ":@"); i >= 0 {
+		id = id[:i]
+	}
+	product, known := devcontainerFeatureProducts[id]
+	if !known {
+		return Reference{}, false
+	}
+
+	// Features accept a bare string as shorthand for the version option
+	version, node := mappingString(feature.value, "version")
+	if node == nil {
+		var ok bool
+		if version, ok = scalarValue(feature.value); !ok {
+			return Reference{}, false
+		}
+		node = feature.value
+	}
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if version == "" || version[0] < '0' || version[0] > '9' {
+		// latest, lts, none and os-provided don't pin a release
+		return Reference{}, false
+	}
+
+	return Reference{
+		Product: product,
+		Version: version,
+		Subject: "devcontainer feature " + id + " " + version,
+		File:    path,
+		Line:    node.Line,
+		Context: map[string]string{"feature": feature.key.Value},
+	}, true
+}
+
+// stripJSONC turns JSON with comments and trailing commas into plain JSON.
+// Removed characters become spaces so that line numbers are preserved.
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, len(data))
+	copy(out, data)
+
+	// Blank out comments
+	forEachOutsideString(out, func(i int) int {
+		switch {
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case out[i] == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+		}
+		return i
+	})
+
+	// Blank out commas that only precede a closing bracket or brace
+	forEachOutsideString(out, func(i int) int {
+		if out[i] == ',' {
+			rest := bytes.TrimLeft(out[i+1:], " \t\r\n")
+			if len(rest) > 0 && (rest[0] == '}' || rest[0] == ']') {
+				out[i] = ' '
+			}
+		}
+		return i
+	})
+	return out
+}
+
+// forEachOutsideString calls fn with the index of every byte of JSON data
+// that is not inside a string literal. fn returns the index to resume from.
+func forEachOutsideString(data []byte, fn func(i int) int) {
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString && c == '\\':
+			i++
+		case inString && c == '"':
+			inString = false
+		case inString:
+		case c == '"':
+			inString = true
+		default:
+			if next := fn(i); next > i {
+				i = next - 1
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStripJSONC(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", `{"a": 1}`, `{"a": 1}`},
+		{"line comment", "{\"a\": 1 // one\n}", "{\"a\": 1       \n}"},
+		{"block comment", "{/* a\nb */\"a\": 1}", "{    \n    \"a\": 1}"},
+		{"trailing comma", `{"a": [1, 2,], }`, `{"a": [1, 2 ]  }`},
+		{"comment markers in strings", `{"url": "http://x/*y*/", "s": "a,}"}`, `{"url": "http://x/*y*/", "s": "a,}"}`},
+		{"escaped quote", `{"s": "\"//", "b": 1,}`, `{"s": "\"//", "b": 1 }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(stripJSONC([]byte(tt.in))); got != tt.want {
+				t.Errorf("stripJSONC(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDevcontainerScan(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		files  map[string]string
+		want   []Reference
+	}{
+		{
+			name: "image and features",
+			config: `{
+	// Base image
+	"image": "mcr.microsoft.com/devcontainers/python:3.8",
+	"features": {
+		"ghcr.io/devcontainers/features/node:1": {"version": "16"},
+		"ghcr.io/devcontainers/features/go:1": "1.21",
+		"ghcr.io/devcontainers/features/ruby:1": {"version": "latest"},
+		"ghcr.io/devcontainers/features/common-utils:2": {},
+	},
+}
+`,
+			want: []Reference{
+				{Image: "mcr.microsoft.com/devcontainers/python:3.8", Line: 3, Context: map[string]string{"devcontainer": "image"}},
+				{Product: "nodejs", Version: "16", Subject: "devcontainer feature node 16", Line: 5, Context: map[string]string{"feature": "ghcr.io/devcontainers/features/node:1"}},
+				{Product: "go", Version: "1.21", Subject: "devcontainer feature go 1.21", Line: 6, Context: map[string]string{"feature": "ghcr.io/devcontainers/features/go:1"}},
+			},
+		},
+		{
+			name: "build",
+			config: `{
+	"build": {"dockerfile": "Dockerfile", "args": {"VARIANT": "18"}}
+}
+`,
+			files: map[string]string{"Dockerfile": "ARG VARIANT=16\nFROM node:${VARIANT}\n"},
+			want: []Reference{
+				{Image: "node:18", Raw: "node:${VARIANT}", Line: 2, Context: map[string]string{"stage": "0", "devcontainer": "build"}},
+			},
+		},
+		{
+			name: "legacy dockerFile that is missing",
+			config: `{"dockerFile": "missing/Dockerfile"}
+`,
+			want: nil,
+		},
+		{
+			name: "compose",
+			config: `{
+	"dockerComposeFile": ["../compose.yaml"],
+	"service": "app"
+}
+`,
+			files: map[string]string{"../compose.yaml": "services:\n  app:\n    image: ruby:2.7\n"},
+			want: []Reference{
+				{Image: "ruby:2.7", Line: 3, Context: map[string]string{"service": "app", "devcontainer": "compose"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), ".devcontainer")
+			for name, content := range tt.files {
+				writeTestFile(t, filepath.Join(dir, name), content)
+			}
+			path := filepath.Join(dir, "devcontainer.json")
+			writeTestFile(t, path, tt.config)
+
+			dockerfiles := NewDockerfileScanner(nil)
+			got, err := NewDevcontainerScanner(dockerfiles, NewComposeScanner(dockerfiles)).Scan(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range tt.want {
+				switch {
+				case tt.want[i].Context["devcontainer"] == "build":
+					tt.want[i].File = filepath.Join(dir, "Dockerfile")
+					tt.want[i].Sources = []string{path}
+				case tt.want[i].Context["devcontainer"] == "compose":
+					tt.want[i].File = filepath.Join(dir, "..", "compose.yaml")
+					tt.want[i].Sources = []string{path}
+				default:
+					tt.want[i].File = path
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DockerfileScanner finds the base images of Dockerfile build stages
+type DockerfileScanner struct {
+	buildArgs map[string]string
+}
+
+// NewDockerfileScanner creates a Dockerfile scanner. buildArgs override the
+// defaults of ARG instructions, like docker build --build-arg.
+func NewDockerfileScanner(buildArgs map[string]string) *DockerfileScanner {
+	return &DockerfileScanner{buildArgs: buildArgs}
+}
+
+// Name identifies the scanner
+func (s *DockerfileScanner) Name() string {
+	return "dockerfile"
+}
+
+// Match recognizes Dockerfile, Containerfile, Dockerfile.* and *.Dockerfile
+func (s *DockerfileScanner) Match(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	for _, base := range []string{"dockerfile", "containerfile"} {
+		if name == base || strings.HasPrefix(name, base+".") || strings.HasSuffix(name, "."+base) {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan returns the external image of every FROM instruction. Stages built
+// FROM an earlier stage and FROM scratch are skipped.
+func (s *DockerfileScanner) Scan(path string) ([]Reference, error) {
+	return s.ScanWithArgs(path, nil)
+}
+
+// ScanWithArgs scans like Scan, with extra build arguments that take
+// precedence over the scanner's own
+func (s *DockerfileScanner) ScanWithArgs(path string, buildArgs map[string]string) ([]Reference, error) {
+	instructions, err := readDockerfile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[string]string, len(s.buildArgs)+len(buildArgs))
+	for k, v := range s.buildArgs {
+		overrides[k] = v
+	}
+	for k, v := range buildArgs {
+		overrides[k] = v
+	}
+
+	var refs []Reference
+	// Only global ARGs, declared before the first FROM, apply to FROM
+	// lines; ENV and stage-level ARGs never do
+	globals := make(map[string]string)
+	seenFrom := false
+	stages := make(map[string]bool)
+	stageIndex := 0
+
+	for _, inst := range instructions {
+		switch inst.keyword {
+		case "ARG":
+			if !seenFrom {
+				declareArgs(inst.args, globals, overrides)
+			}
+			continue
+		case "FROM":
+			seenFrom = true
+		default:
+			continue
+		}
+
+		from, err := parseFrom(inst.args)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", inst.line, err)
+		}
+
+		stage := from.name
+		if stage == "" {
+			stage = strconv.Itoa(stageIndex)
+		}
+		stageIndex++
+		context := map[string]string{"stage": stage}
+
+		x := &expander{lookup: lookupIn(globals)}
+		ref := newReference(from.image, x, path, inst.line, context)
+
+		isStage := stages[strings.ToLower(ref.Image)]
+		if from.name != "" {
+			stages[strings.ToLower(from.name)] = true
+		}
+		if len(ref.Unresolved) == 0 && (isStage || strings.EqualFold(ref.Image, "scratch")) {
+			continue
+		}
+
+		if from.platform != "" {
+			// Platform ARGs such as BUILDPLATFORM are predefined by
+			// BuildKit, so keep the raw value when they can't be expanded
+			px := &expander{lookup: lookupIn(globals)}
+			if platform := px.expand(from.platform); len(px.unresolved) == 0 {
+				context["platform"] = platform
+			} else {
+				context["platform"] = from.platform
+			}
+		}
+
+		refs = append(refs, ref)
+	}
+
+	return refs, nil
+}
+
+// declareArgs records the variables of an ARG instruction such as
+// "NODE_VERSION=18 DISTRO". Overrides win over defaults; an ARG without
+// default or override is declared but unset.
+func declareArgs(args string, vars, overrides map[string]string) {
+	for _, field := range strings.Fields(args) {
+		name, value, hasDefault := strings.Cut(field, "=")
+		if override, ok := overrides[name]; ok {
+			vars[name] = override
+			continue
+		}
+		if !hasDefault {
+			delete(vars, name)
+			continue
+		}
+		x := &expander{lookup: lookupIn(vars)}
+		vars[name] = x.expand(unquote(value))
+	}
+}
+
+// lookupIn returns a lookup function over vars
+func lookupIn(vars map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+}
+
+// unquote strips one level of matching single or double quotes
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// instruction is a Dockerfile instruction with continuation lines joined
+type instruction struct {
+	keyword string
+	args    string
+	line    int
+}
+
+// readDockerfile splits a Dockerfile into instructions, honoring the escape
+// parser directive, comments, line continuations and heredocs
+func readDockerfile(path string) ([]instruction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		instructions []instruction
+		current      strings.Builder
+		startLine    int
+		heredocs     []string
+		escape       = '\\'
+		directives   = true
+	)
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+
+		// Skip heredoc bodies, which may contain lines that look like instructions
+		if len(heredocs) > 0 {
+			if trimmed == heredocs[0] {
+				heredocs = heredocs[1:]
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "#") {
+			if directives {
+				if key, value, ok := strings.Cut(strings.TrimSpace(trimmed[1:]), "="); ok {
+					if strings.EqualFold(strings.TrimSpace(key), "escape") && strings.TrimSpace(value) == "`" {
+						escape = '`'
+					}
+					continue
+				}
+			}
+			directives = false
+			continue
+		}
+		directives = false
+
+		if trimmed == "" {
+			continue
+		}
+
+		if current.Len() == 0 {
+			startLine = lineNo
+		}
+
+		if strings.HasSuffix(trimmed, string(escape)) {
+			current.WriteString(strings.TrimSuffix(trimmed, string(escape)))
+			current.WriteString(" ")
+			continue
+		}
+		current.WriteString(trimmed)
+
+		keyword, args, _ := strings.Cut(current.String(), " ")
+		inst := instruction{
+			keyword: strings.ToUpper(keyword),
+			args:    strings.TrimSpace(args),
+			line:    startLine,
+		}
+		instructions = append(instructions, inst)
+		current.Reset()
+
+		heredocs = heredocTerminators(inst.keyword, inst.args)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return instructions, nil
+}
+
+// heredocTerminators returns the terminators of the heredocs opened by an
+// instruction, in order, such as EOF in "<<EOF" or "<<-'EOF'". Only RUN, COPY
+// and ADD take heredocs; elsewhere, and when << is not followed by a word, as
+// in the shift of "$((1<<2))" or the here-string "<<<", it is not a heredoc.
+func heredocTerminators(keyword, args string) []string {
+	switch keyword {
+	case "RUN", "COPY", "ADD":
+	default:
+		return nil
+	}
+
+	var terminators []string
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] != '<' || args[i+1] != '<' {
+			continue
+		}
+		if i > 0 && args[i-1] == '<' || i+2 < len(args) && args[i+2] == '<' {
+			// Here-string
+			i += 2
+			continue
+		}
+		rest := strings.TrimPrefix(args[i+2:], "-")
+		quote := ""
+		if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+			quote, rest = rest[:1], rest[1:]
+		}
+		if rest == "" || !isWordStart(rest[0]) {
+			continue
+		}
+		end := 1
+		for end < len(rest) && isWordChar(rest[end]) {
+			end++
+		}
+		if quote != "" && !strings.HasPrefix(rest[end:], quote) {
+			continue
+		}
+		terminators = append(terminators, rest[:end])
+		i += 1 + end
+	}
+	return terminators
+}
+
+// isWordStart reports whether c can start a heredoc word
+func isWordStart(c byte) bool {
+	return c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+// isWordChar reports whether c can continue a heredoc word
+func isWordChar(c byte) bool {
+	return isWordStart(c) || c >= '0' && c <= '9'
+}
+
+// fromInstruction holds the parts of a FROM instruction
+type fromInstruction struct {
+	image    string
+	name     string
+	platform string
+}
+
+// parseFrom parses "[--platform=<platform>] <image> [AS <name>]"
+func parseFrom(args string) (fromInstruction, error) {
+	var from fromInstruction
+	fields := strings.Fields(args)
+
+	for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+		flag, value, _ := strings.Cut(strings.TrimPrefix(fields[0], "--"), "=")
+		if strings.EqualFold(flag, "platform") {
+			from.platform = value
+		}
+		fields = fields[1:]
+	}
+
+	switch {
+	case len(fields) == 1:
+		from.image = fields[0]
+	case len(fields) == 3 && strings.EqualFold(fields[1], "AS"):
+		from.image = fields[0]
+		from.name = fields[2]
+	default:
+		return from, fmt.Errorf("invalid FROM instruction %q", args)
+	}
+
+	return from, nil
+}
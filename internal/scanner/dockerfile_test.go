@@ -0,0 +1,176 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHeredocTerminators(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyword string
+		args    string
+		want    []string
+	}{
+		{"plain", "RUN", "<<EOF", []string{"EOF"}},
+		{"command", "RUN", "cat <<EOF > /etc/app.conf", []string{"EOF"}},
+		{"dash", "RUN", "<<-EOT", []string{"EOT"}},
+		{"single quoted", "RUN", "<<'EOF'", []string{"EOF"}},
+		{"double quoted", "COPY", `<<"END" /app/script.sh`, []string{"END"}},
+		{"dash quoted", "RUN", "<<-'EOF' bash", []string{"EOF"}},
+		{"underscore", "ADD", "<<_DATA /data", []string{"_DATA"}},
+		{"several", "COPY", "<<FILE1 <<FILE2 /dest/", []string{"FILE1", "FILE2"}},
+		{"bit shift", "RUN", "echo $((1<<2))", nil},
+		{"bit shift with spaces", "RUN", "echo $((1 << 2))", nil},
+		{"bit shift of variable", "RUN", "echo $((1<<$N))", nil},
+		{"here-string", "RUN", `cat <<<"hello"`, nil},
+		{"unterminated quote", "RUN", `<<"EOF`, nil},
+		{"starts with digit", "RUN", "<<1EOF", nil},
+		{"other instruction", "LABEL", "description=<<EOF", nil},
+		{"env", "ENV", "SHIFT=<<EOF", nil},
+		{"none", "RUN", "apt-get update", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := heredocTerminators(tt.keyword, tt.args)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("heredocTerminators(%q, %q) = %q, want %q", tt.keyword, tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDockerfileScan(t *testing.T) {
+	tests := []struct {
+		name       string
+		dockerfile string
+		buildArgs  map[string]string
+		want       []Reference
+	}{
+		{
+			name:       "single stage",
+			dockerfile: "FROM node:18-alpine\n",
+			want:       []Reference{{Image: "node:18-alpine", Line: 1, Context: map[string]string{"stage": "0"}}},
+		},
+		{
+			name: "stages and scratch",
+			dockerfile: `FROM golang:1.21 AS build
+RUN go build ./...
+FROM build AS test
+FROM scratch
+COPY --from=build /app /app
+`,
+			want: []Reference{{Image: "golang:1.21", Line: 1, Context: map[string]string{"stage": "build"}}},
+		},
+		{
+			name: "bit shift is not a heredoc",
+			dockerfile: `ARG X=3.12
+FROM alpine:3.19
+RUN echo $((1<<2))
+FROM python:${X}
+`,
+			want: []Reference{
+				{Image: "alpine:3.19", Line: 2, Context: map[string]string{"stage": "0"}},
+				{Image: "python:3.12", Raw: "python:${X}", Line: 4, Context: map[string]string{"stage": "1"}},
+			},
+		},
+		{
+			name: "heredoc body",
+			dockerfile: `FROM alpine:3.19
+RUN <<EOF
+FROM ubuntu:14.04
+EOF
+COPY <<-'A' <<B /etc/
+	FROM debian:8
+	A
+FROM centos:6
+B
+FROM python:3.12
+`,
+			want: []Reference{
+				{Image: "alpine:3.19", Line: 1, Context: map[string]string{"stage": "0"}},
+				{Image: "python:3.12", Line: 10, Context: map[string]string{"stage": "1"}},
+			},
+		},
+		{
+			name: "continuation and comments",
+			dockerfile: `# syntax=docker/dockerfile:1
+# A comment
+FROM --platform=linux/amd64 \
+    node:20 AS web
+`,
+			want: []Reference{{Image: "node:20", Line: 3, Context: map[string]string{"stage": "web", "platform": "linux/amd64"}}},
+		},
+		{
+			name:       "escape directive",
+			dockerfile: "# escape=`\nFROM mcr.microsoft.com/windows/servercore:ltsc2019 `\n  AS base\n",
+			want:       []Reference{{Image: "mcr.microsoft.com/windows/servercore:ltsc2019", Line: 2, Context: map[string]string{"stage": "base"}}},
+		},
+		{
+			name: "build arg overrides default",
+			dockerfile: `ARG NODE_VERSION=18
+FROM node:${NODE_VERSION}
+`,
+			buildArgs: map[string]string{"NODE_VERSION": "20"},
+			want:      []Reference{{Image: "node:20", Raw: "node:${NODE_VERSION}", Line: 2, Context: map[string]string{"stage": "0"}}},
+		},
+		{
+			name: "unresolved argument",
+			dockerfile: `ARG X
+FROM node:${X}
+`,
+			want: []Reference{{Image: "node:${X}", Line: 2, Context: map[string]string{"stage": "0"}, Unresolved: []string{"X"}}},
+		},
+		{
+			name: "stage arguments do not apply to FROM",
+			dockerfile: `FROM alpine:3.19
+ARG V=3.12
+FROM python:${V}
+`,
+			want: []Reference{
+				{Image: "alpine:3.19", Line: 1, Context: map[string]string{"stage": "0"}},
+				{Image: "python:${V}", Line: 3, Context: map[string]string{"stage": "1"}, Unresolved: []string{"V"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "Dockerfile")
+			if err := os.WriteFile(path, []byte(tt.dockerfile), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := NewDockerfileScanner(tt.buildArgs).Scan(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range tt.want {
+				tt.want[i].File = path
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDockerfileMatch(t *testing.T) {
+	tests := map[string]bool{
+		"Dockerfile":          true,
+		"dockerfile":          true,
+		"Containerfile":       true,
+		"Dockerfile.prod":     true,
+		"api.Dockerfile":      true,
+		"docs/Dockerfile":     true,
+		"Dockerfile-template": false,
+		"docker-compose.yml":  false,
+		"README.md":           false,
+	}
+	s := NewDockerfileScanner(nil)
+	for path, want := range tests {
+		if got := s.Match(path); got != want {
+			t.Errorf("Match(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"gopkg.in/yaml.v3"
+)
+
+// ECSScanner finds container images in Amazon ECS task definitions
+type ECSScanner struct{}
+
+// NewECSScanner creates an ECS task definition scanner
+func NewECSScanner() *ECSScanner {
+	return &ECSScanner{}
+}
+
+// Name identifies the scanner
+func (s *ECSScanner) Name() string {
+	return "ecs"
+}
+
+// Match recognizes JSON files named after task definitions, such as
+// task-definition.json or app.taskdef.json. Task definitions with other
+// names are picked up by the generic manifest scanner.
+func (s *ECSScanner) Match(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	if filepath.Ext(name) != ".json" {
+		return false
+	}
+	return strings.Contains(name, "task-definition") ||
+		strings.Contains(name, "task_definition") ||
+		strings.Contains(name, "taskdef")
+}
+
+// Scan returns the image of every container definition
+func (s *ECSScanner) Scan(path string) ([]Reference, error) {
+	docs, err := readYAML(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Reference
+	for _, doc := range docs {
+		found, _ := scanECSTaskDefinition(doc, path)
+		refs = append(refs, found...)
+	}
+	return refs, nil
+}
+
+// scanECSTaskDefinition returns the images of a task definition, as
+// registered or as output by aws ecs describe-task-definition. ok is false
+// when obj is not a task definition.
+func scanECSTaskDefinition(obj *yaml.Node, path string) (refs []Reference, ok bool) {
+	if wrapped := mappingValue(obj, "taskDefinition"); wrapped != nil {
+		obj = wrapped
+	}
+	definitions := mappingValue(obj, "containerDefinitions")
+	if definitions == nil {
+		return nil, false
+	}
+	context := map[string]string{}
+	if family, _ := mappingString(obj, "family"); family != "" {
+		context["family"] = family
+	}
+	return ecsContainerImages(definitions, path, 0, context), true
+}
+
+// ecsContainerImages returns the image of each container definition. A
+// non-zero line overrides the line of every image, and context is added to
+// theirs.
+func ecsContainerImages(definitions *yaml.Node, path string, line int, context map[string]string) []Reference {
+	var refs []Reference
+	for _, container := range sequenceItems(definitions) {
+		imageName, node := mappingString(container, "image")
+		if node == nil {
+			continue
+		}
+		name, _ := mappingString(container, "name")
+
+		ctx := map[string]string{"container": name}
+		for k, v := range context {
+			ctx[k] = v
+		}
+		ref := Reference{
+			Image:   imageName,
+			File:    path,
+			Line:    node.Line,
+			Context: ctx,
+		}
+		if line != 0 {
+			ref.Line = line
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// ecsContainerDefinitions reads the images of an aws_ecs_task_definition.
+// Definitions written as jsonencode([...]) are read in place, so that each
+// image keeps its own line; others, such as file("task.json"), are evaluated
+// and reported at the container_definitions line.
+func ecsContainerDefinitions(m *terraformModule, r terraformResource) []Reference {
+	attr := r.body.Attributes["container_definitions"]
+	if attr == nil {
+		return nil
+	}
+	context := map[string]string{"resource": r.address}
+	if family, _, unresolved := m.stringAttribute(r.body, "family"); len(unresolved) == 0 && family != "" {
+		context["family"] = family
+	}
+
+	if call, ok := attr.Expr.(*hclsyntax.FunctionCallExpr); ok && call.Name == "jsonencode" && len(call.Args) == 1 {
+		if list, ok := call.Args[0].(*hclsyntax.TupleConsExpr); ok {
+			var refs []Reference
+			for _, item := range list.Exprs {
+				container, ok := item.(*hclsyntax.ObjectConsExpr)
+				if !ok {
+					continue
+				}
+				var name string
+				var image hclsyntax.Expression
+				for _, field := range container.Items {
+					switch objectKey(field.KeyExpr) {
+					case "name":
+						name, _ = m.stringValue(field.ValueExpr, "name")
+					case "image":
+						image = field.ValueExpr
+					}
+				}
+				if image == nil {
+					continue
+				}
+				ctx := map[string]string{"container": name}
+				for k, v := range context {
+					ctx[k] = v
+				}
+				refs = append(refs, m.imageReference(image, r.src, r.path, ctx))
+			}
+			return refs
+		}
+	}
+
+	value, line, unresolved := m.stringAttribute(r.body, "container_definitions")
+	if len(unresolved) > 0 {
+		return []Reference{{
+			Image:      "container_definitions",
+			File:       r.path,
+			Line:       line,
+			Context:    context,
+			Unresolved: unresolved,
+		}}
+	}
+	docs, err := parseYAML([]byte(value))
+	if err != nil {
+		return nil
+	}
+	// container_definitions is the bare list of definitions
+	var refs []Reference
+	for _, doc := range docs {
+		refs = append(refs, ecsContainerImages(doc, r.path, line, context)...)
+	}
+	return refs
+}
+
+// objectKey returns the name of an object key, written bare or quoted
+func objectKey(expr hclsyntax.Expression) string {
+	if key := hcl.ExprAsKeyword(expr); key != "" {
+		return key
+	}
+	if value, diags := expr.Value(nil); !diags.HasErrors() && value.Type() == cty.String {
+		return value.AsString()
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestECSScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		taskdef string
+		want    []Reference
+	}{
+		{
+			name: "registered task definition",
+			taskdef: `{
+  "family": "web",
+  "containerDefinitions": [
+    {"name": "app", "image": "node:16"},
+    {"name": "proxy", "image": "nginx:1.20"},
+    {"name": "no-image"}
+  ]
+}
+`,
+			want: []Reference{
+				{Image: "node:16", Line: 4, Context: map[string]string{"family": "web", "container": "app"}},
+				{Image: "nginx:1.20", Line: 5, Context: map[string]string{"family": "web", "container": "proxy"}},
+			},
+		},
+		{
+			name: "describe-task-definition output",
+			taskdef: `{
+  "taskDefinition": {
+    "containerDefinitions": [{"name": "worker", "image": "python:3.8"}]
+  }
+}
+`,
+			want: []Reference{
+				{Image: "python:3.8", Line: 3, Context: map[string]string{"container": "worker"}},
+			},
+		},
+		{
+			name:    "not a task definition",
+			taskdef: `{"family": "web"}`,
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "task-definition.json")
+			writeTestFile(t, path, tt.taskdef)
+
+			got, err := NewECSScanner().Scan(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range tt.want {
+				tt.want[i].File = path
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestECSMatch(t *testing.T) {
+	s := NewECSScanner()
+	for path, want := range map[string]bool{
+		"task-definition.json":     true,
+		"deploy/app.taskdef.json":  true,
+		"web_task_definition.json": true,
+		"task-definition.yaml":     false,
+		"appspec.json":             false,
+		"task-definition.json.bak": false,
+	} {
+		if got := s.Match(path); got != want {
+			t.Errorf("Match(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestTerraformECSTaskDefinition(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "variables.tf"), "variable \"node\" {\n  default = \"18\"\n}\nvariable \"tag\" {}\n")
+	writeTestFile(t, filepath.Join(dir, "task.json"), `[{"name": "worker", "image": "python:3.9"}]`)
+	path := filepath.Join(dir, "ecs.tf")
+	writeTestFile(t, path, `resource "aws_ecs_task_definition" "web" {
+  family = "web"
+  container_definitions = jsonencode([
+    {
+      name  = "app"
+      image = "node:${var.node}"
+    },
+    {
+      name  = "sidecar"
+      image = "example/sidecar:${var.tag}"
+    },
+  ])
+}
+
+resource "aws_ecs_task_definition" "worker" {
+  family                = "worker"
+  container_definitions = file("${path.module}/task.json")
+}
+`)
+
+	got, err := NewTerraformScanner().Scan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The module's variables and the files it reads are sources of every
+	// reference of the file
+	sources := []string{filepath.Join(dir, "variables.tf"), filepath.Join(dir, "task.json")}
+	want := []Reference{
+		{Image: "node:18", Raw: "node:${var.node}", File: path, Line: 6, Context: map[string]string{"resource": "aws_ecs_task_definition.web", "family": "web", "container": "app"}, Sources: sources},
+		{Image: "example/sidecar:${var.tag}", File: path, Line: 10, Context: map[string]string{"resource": "aws_ecs_task_definition.web", "family": "web", "container": "sidecar"}, Unresolved: []string{"var.tag"}, Sources: sources},
+		{Image: "python:3.9", File: path, Line: 17, Context: map[string]string{"resource": "aws_ecs_task_definition.worker", "family": "worker", "container": "worker"}, Sources: sources},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan() = %+v, want %+v", got, want)
+	}
+}
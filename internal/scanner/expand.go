@@ -0,0 +1,150 @@
+package scanner
+
+import (
+	"strings"
+)
+
+// expander substitutes shell-style variable references: $VAR, ${VAR},
+// ${VAR:-default}, ${VAR-default}, ${VAR:+alternate}, ${VAR+alternate},
+// ${VAR:?error} and ${VAR?error}
+type expander struct {
+	lookup func(name string) (string, bool)
+	// dollarEscape selects "$$" as the escape for a literal "$" (compose)
+	// instead of a backslash (Dockerfile)
+	dollarEscape bool
+	// unresolved collects variables that were needed but not set
+	unresolved []string
+}
+
+// expand returns s with every variable reference substituted
+func (x *expander) expand(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if !x.dollarEscape && c == '\\' && i+1 < len(s) && s[i+1] == '$' {
+			b.WriteByte('$')
+			i++
+			continue
+		}
+		if c != '$' || i+1 >= len(s) {
+			b.WriteByte(c)
+			continue
+		}
+
+		next := s[i+1]
+		switch {
+		case x.dollarEscape && next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := matchingBrace(s, i+1)
+			if end < 0 {
+				b.WriteString(s[i:])
+				return b.String()
+			}
+			b.WriteString(x.expandBraced(s[i+2 : end]))
+			i = end
+		case isNameStart(next):
+			j := i + 1
+			for j < len(s) && isNameChar(s[j]) {
+				j++
+			}
+			b.WriteString(x.value(s[i+1 : j]))
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// expandBraced expands the inside of ${...}
+func (x *expander) expandBraced(expr string) string {
+	j := 0
+	for j < len(expr) && isNameChar(expr[j]) {
+		j++
+	}
+	name, rest := expr[:j], expr[j:]
+	if rest == "" {
+		return x.value(name)
+	}
+
+	value, set := x.lookup(name)
+	nonEmpty := set && value != ""
+
+	colon := strings.HasPrefix(rest, ":")
+	rest = strings.TrimPrefix(rest, ":")
+	if rest == "" {
+		return x.value(name)
+	}
+	op, word := rest[0], rest[1:]
+	present := set
+	if colon {
+		present = nonEmpty
+	}
+
+	switch op {
+	case '-':
+		if present {
+			return value
+		}
+		return x.expand(word)
+	case '+':
+		if present {
+			return x.expand(word)
+		}
+		return ""
+	case '?':
+		if present {
+			return value
+		}
+		x.markUnresolved(name)
+		return ""
+	default:
+		return x.value(name)
+	}
+}
+
+// value looks up a plain variable reference
+func (x *expander) value(name string) string {
+	value, ok := x.lookup(name)
+	if !ok {
+		x.markUnresolved(name)
+	}
+	return value
+}
+
+func (x *expander) markUnresolved(name string) {
+	for _, n := range x.unresolved {
+		if n == name {
+			return
+		}
+	}
+	x.unresolved = append(x.unresolved, name)
+}
+
+// matchingBrace returns the index of the brace closing the one at open
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9'
+}
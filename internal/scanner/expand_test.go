@@ -0,0 +1,55 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	vars := map[string]string{
+		"NAME":    "node",
+		"VERSION": "18",
+		"EMPTY":   "",
+	}
+	tests := []struct {
+		name         string
+		in           string
+		dollarEscape bool
+		want         string
+		unresolved   []string
+	}{
+		{name: "plain", in: "node:18", want: "node:18"},
+		{name: "bare", in: "$NAME:$VERSION", want: "node:18"},
+		{name: "braced", in: "${NAME}:${VERSION}-alpine", want: "node:18-alpine"},
+		{name: "unset", in: "node:${MISSING}", want: "node:", unresolved: []string{"MISSING"}},
+		{name: "unset reported once", in: "$A$A${B}", want: "", unresolved: []string{"A", "B"}},
+		{name: "default when unset", in: "node:${MISSING:-20}", want: "node:20"},
+		{name: "colon default when empty", in: "node:${EMPTY:-20}", want: "node:20"},
+		{name: "default keeps empty", in: "node:${EMPTY-20}", want: "node:"},
+		{name: "default keeps set", in: "node:${VERSION:-20}", want: "node:18"},
+		{name: "nested default", in: "${MISSING:-${NAME}}:${VERSION}", want: "node:18"},
+		{name: "alternate when set", in: "${VERSION:+v}${VERSION}", want: "v18"},
+		{name: "alternate when unset", in: "${MISSING+x}node", want: "node"},
+		{name: "alternate when empty", in: "${EMPTY:+x}${EMPTY+y}", want: "y"},
+		{name: "required set", in: "${VERSION:?version required}", want: "18"},
+		{name: "required unset", in: "node:${MISSING:?version required}", want: "node:", unresolved: []string{"MISSING"}},
+		{name: "required empty", in: "node:${EMPTY:?}", want: "node:", unresolved: []string{"EMPTY"}},
+		{name: "backslash escape", in: `price \$5`, want: "price $5"},
+		{name: "dollar escape", in: "$$NAME", dollarEscape: true, want: "$NAME"},
+		{name: "backslash is literal with dollar escape", in: `\$NAME`, dollarEscape: true, want: `\node`},
+		{name: "trailing dollar", in: "cost$", want: "cost$"},
+		{name: "dollar before digit", in: "$1", want: "$1"},
+		{name: "unterminated brace", in: "node:${VERSION", want: "node:${VERSION"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := &expander{lookup: lookupIn(vars), dollarEscape: tt.dollarEscape}
+			if got := x.expand(tt.in); got != tt.want {
+				t.Errorf("expand(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			if !reflect.DeepEqual(x.unresolved, tt.unresolved) {
+				t.Errorf("expand(%q) unresolved = %q, want %q", tt.in, x.unresolved, tt.unresolved)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package scanner
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var helmValuesFileName = regexp.MustCompile(`^(values([.-][\w.-]+)?|[\w.-]+[.-]values)\.ya?ml$`)
+
+// HelmValuesScanner finds images declared in Helm chart values without
+// rendering the chart
+type HelmValuesScanner struct {
+	overrides []ValuesFile
+}
+
+// ValuesFile is a values file layered over the values.yaml of a chart
+type ValuesFile struct {
+	// Chart is the directory of the chart
+	Chart string
+	Path  string
+}
+
+// NewHelmValuesScanner creates a values scanner. Override files are layered
+// over the values.yaml of their chart in order, like helm install -f.
+func NewHelmValuesScanner(overrides []ValuesFile) *HelmValuesScanner {
+	return &HelmValuesScanner{overrides: overrides}
+}
+
+// Name identifies the scanner
+func (s *HelmValuesScanner) Name() string {
+	return "helm-values"
+}
+
+// Match recognizes values.yaml, values-<env>.yaml and <env>.values.yaml
+func (s *HelmValuesScanner) Match(path string) bool {
+	return helmValuesFileName.MatchString(strings.ToLower(filepath.Base(path)))
+}
+
+// Scan returns the images declared in a values file, after layering the
+// overrides when it is a chart's default values.yaml
+func (s *HelmValuesScanner) Scan(path string) ([]Reference, error) {
+	files := []string{path}
+	if IsChartValues(path) {
+		for _, override := range s.overrides {
+			if sameDir(override.Chart, filepath.Dir(path)) {
+				files = append(files, override.Path)
+			}
+		}
+	}
+
+	origins := make(map[*yaml.Node]string)
+	var values *yaml.Node
+	for _, file := range files {
+		docs, err := readYAML(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, doc := range docs {
+			recordOrigin(doc, file, origins)
+			values = mergeValues(values, doc)
+		}
+	}
+	if values == nil {
+		return nil, nil
+	}
+
+	finder := &helmImageFinder{
+		origins:    origins,
+		appVersion: chartAppVersion(filepath.Dir(path)),
+	}
+	finder.walk(values, nil)
+	// An image may take its repository and tag from different files
+	addSources(finder.refs, files...)
+	if chart := chartFile(filepath.Dir(path)); chart != "" {
+		addSources(finder.refs, chart)
+	}
+	return finder.refs, nil
+}
+
+// IsChartValues reports whether path is the default values file of a chart
+func IsChartValues(path string) bool {
+	base := strings.ToLower(filepath.Base(path))
+	return base == "values.yaml" || base == "values.yml"
+}
+
+// sameDir reports whether two paths name the same directory
+func sameDir(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+// recordOrigin remembers the file every node of a document comes from
+func recordOrigin(n *yaml.Node, file string, origins map[*yaml.Node]string) {
+	if n == nil {
+		return
+	}
+	if _, seen := origins[n]; seen {
+		return
+	}
+	origins[n] = file
+	for _, child := range n.Content {
+		recordOrigin(child, file, origins)
+	}
+	if n.Alias != nil {
+		recordOrigin(n.Alias, file, origins)
+	}
+}
+
+// mergeValues deep-merges overlay into base the way Helm coalesces values:
+// mappings merge key by key, anything else is replaced, and null deletes
+func mergeValues(base, overlay *yaml.Node) *yaml.Node {
+	overlay = resolve(overlay)
+	base = resolve(base)
+	if base == nil || base.Kind != yaml.MappingNode || overlay.Kind != yaml.MappingNode {
+		return overlay
+	}
+
+	merged := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: base.Line}
+	overlayEntries := mappingEntries(overlay)
+	overridden := make(map[string]*yaml.Node, len(overlayEntries))
+	for _, e := range overlayEntries {
+		overridden[e.key.Value] = e.value
+	}
+
+	for _, e := range mappingEntries(base) {
+		value := e.value
+		if o, ok := overridden[e.key.Value]; ok {
+			delete(overridden, e.key.Value)
+			if o.Tag == "!!null" {
+				continue
+			}
+			value = mergeValues(value, o)
+		}
+		merged.Content = append(merged.Content, e.key, value)
+	}
+	for _, e := range overlayEntries {
+		if _, pending := overridden[e.key.Value]; pending && e.value.Tag != "!!null" {
+			merged.Content = append(merged.Content, e.key, e.value)
+		}
+	}
+	return merged
+}
+
+// chartAppVersion reads appVersion from the Chart.yaml next to the values,
+// which charts commonly use as the default image tag
+func chartAppVersion(dir string) string {
+	path := chartFile(dir)
+	if path == "" {
+		return ""
+	}
+	docs, err := readYAML(path)
+	if err != nil || len(docs) == 0 {
+		return ""
+	}
+	appVersion, _ := mappingString(docs[0], "appVersion")
+	return appVersion
+}
+
+// chartFile returns the Chart.yaml of the chart in dir, or ""
+func chartFile(dir string) string {
+	for _, name := range []string{"Chart.yaml", "Chart.yml"} {
+		if path := filepath.Join(dir, name); FileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// helmImageFinder walks merged values looking for image declarations
+type helmImageFinder struct {
+	origins    map[*yaml.Node]string
+	appVersion string
+	refs       []Reference
+}
+
+// walk visits every mapping and sequence below n. path is the dotted key
+// path of n.
+func (f *helmImageFinder) walk(n *yaml.Node, path []string) {
+	n = resolve(n)
+	switch {
+	case n == nil:
+		return
+	case n.Kind == yaml.SequenceNode:
+		for i, item := range sequenceItems(n) {
+			f.walk(item, indexPath(path, i))
+		}
+		return
+	case n.Kind != yaml.MappingNode:
+		return
+	}
+
+	// Shape: {registry, repository, tag, digest}
+	if f.addRepositoryImage(n, path) {
+		return
+	}
+
+	var tagKey string
+	for _, key := range []string{"tag", "imageTag"} {
+		if _, node := mappingString(n, key); node != nil {
+			tagKey = key
+			break
+		}
+	}
+
+	for _, e := range mappingEntries(n) {
+		key := e.key.Value
+		if value, ok := scalarValue(e.value); ok && isImageKey(key) {
+			// Shapes: image: nginx:1.20, or image: nginx with a sibling tag
+			if tagKey != "" && !strings.Contains(lastSegment(value), ":") {
+				tag, tagNode := mappingString(n, tagKey)
+				f.add(imageReference("", value, tag, ""), tagNode, append(path, key))
+			} else if strings.Contains(lastSegment(value), ":") || strings.Contains(value, "@") {
+				f.add(value, e.value, append(path, key))
+			}
+			continue
+		}
+
+		if isImageKey(key) {
+			// Shape: image: {name, tag}
+			if name, nameNode := mappingString(e.value, "name"); nameNode != nil {
+				if _, repo := mappingString(e.value, "repository"); repo == nil {
+					tag, tagNode := mappingString(e.value, "tag")
+					if tagNode == nil {
+						tagNode = nameNode
+					}
+					registry, _ := mappingString(e.value, "registry")
+					f.add(imageReference(registry, name, f.defaultTag(tag), ""), tagNode, append(path, key))
+					continue
+				}
+			}
+		}
+
+		f.walk(e.value, append(path, key))
+	}
+}
+
+// addRepositoryImage reports the image of a mapping with a repository key.
+// Other things have repositories too, such as git sources, so the mapping
+// must be under an image key or have a tag or a digest, and URLs are left
+// out.
+func (f *helmImageFinder) addRepositoryImage(n *yaml.Node, path []string) bool {
+	repository, repoNode := mappingString(n, "repository")
+	if repoNode == nil || repository == "" || strings.Contains(repository, "://") {
+		return false
+	}
+	registry, _ := mappingString(n, "registry")
+	tag, tagNode := mappingString(n, "tag")
+	digest, digestNode := mappingString(n, "digest")
+	if tagNode == nil && digestNode == nil && !underImageKey(path) {
+		return false
+	}
+
+	node := tagNode
+	if node == nil {
+		node = repoNode
+	}
+	f.add(imageReference(registry, repository, f.defaultTag(tag), digest), node, path)
+	return true
+}
+
+// defaultTag falls back to the chart appVersion for an empty tag
+func (f *helmImageFinder) defaultTag(tag string) string {
+	if tag == "" {
+		return f.appVersion
+	}
+	return tag
+}
+
+func (f *helmImageFinder) add(imageName string, node *yaml.Node, path []string) {
+	if imageName == "" || strings.Contains(imageName, "{{") {
+		return
+	}
+	f.refs = append(f.refs, Reference{
+		Image:   imageName,
+		File:    f.origins[node],
+		Line:    node.Line,
+		Context: map[string]string{"values": strings.Join(path, ".")},
+	})
+}
+
+// imageReference assembles a full reference from Helm-style parts. The
+// digest is only used when there is no tag, since the tag carries the version.
+func imageReference(registry, repository, tag, digest string) string {
+	ref := repository
+	if registry != "" {
+		ref = strings.TrimSuffix(registry, "/") + "/" + ref
+	}
+	switch {
+	case tag != "":
+		ref += ":" + tag
+	case digest != "":
+		ref += "@" + digest
+	}
+	return ref
+}
+
+// indexPath returns path with an index appended to its last key
+func indexPath(path []string, i int) []string {
+	indexed := append([]string(nil), path...)
+	if len(indexed) == 0 {
+		return []string{fmt.Sprintf("[%d]", i)}
+	}
+	indexed[len(indexed)-1] += fmt.Sprintf("[%d]", i)
+	return indexed
+}
+
+// underImageKey reports whether the last key of path names images, such as
+// image, images[0] or sidecarImage
+func underImageKey(path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	key := path[len(path)-1]
+	if i := strings.Index(key, "["); i >= 0 {
+		key = key[:i]
+	}
+	return strings.Contains(strings.ToLower(key), "image")
+}
+
+// isImageKey reports whether a values key conventionally holds an image
+func isImageKey(key string) bool {
+	return key == "image" || strings.HasSuffix(key, "Image")
+}
+
+// lastSegment returns the part of a reference after the last slash, where
+// a tag separator may appear without being confused with a registry port
+func lastSegment(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
@@ -0,0 +1,158 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHelmValuesScan(t *testing.T) {
+	tests := []struct {
+		name   string
+		values string
+		want   []string
+	}{
+		{
+			name: "repository and tag",
+			values: `image:
+  registry: docker.io
+  repository: bitnami/redis
+  tag: 7.0.5
+`,
+			want: []string{"docker.io/bitnami/redis:7.0.5"},
+		},
+		{
+			name: "repository under image key uses appVersion",
+			values: `image:
+  repository: nginx
+  tag: ""
+`,
+			want: []string{"nginx:1.25"},
+		},
+		{
+			name: "images list",
+			values: `images:
+  - repository: nginx
+  - repository: redis
+`,
+			want: []string{"nginx:1.25", "redis:1.25"},
+		},
+		{
+			name: "git source is not an image",
+			values: `source:
+  repository: https://github.com/example/app.git
+  tag: v1.2.0
+config:
+  repository: example/app
+  branch: main
+`,
+			want: nil,
+		},
+		{
+			name: "image string and sibling tag",
+			values: `web:
+  image: node:20
+worker:
+  image: python
+  tag: "3.12"
+`,
+			want: []string{"node:20", "python:3.12"},
+		},
+		{
+			name: "image name mapping",
+			values: `sidecarImage:
+  name: busybox
+  tag: "1.36"
+`,
+			want: []string{"busybox:1.36"},
+		},
+		{
+			name:   "template",
+			values: "image: \"{{ .Values.registry }}/app:1.0\"\n",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestFile(t, filepath.Join(dir, "Chart.yaml"), "name: app\nappVersion: \"1.25\"\n")
+			path := filepath.Join(dir, "values.yaml")
+			writeTestFile(t, path, tt.values)
+
+			refs, err := NewHelmValuesScanner(nil).Scan(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := referenceImages(refs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHelmValuesOverrides(t *testing.T) {
+	root := t.TempDir()
+	api := filepath.Join(root, "api")
+	web := filepath.Join(root, "web")
+	for _, dir := range []string{api, web} {
+		writeTestFile(t, filepath.Join(dir, "values.yaml"), "image:\n  repository: nginx\n  tag: \"1.20\"\n")
+	}
+	override := filepath.Join(root, "prod.yaml")
+	writeTestFile(t, override, "image:\n  tag: \"1.25\"\n")
+
+	s := NewHelmValuesScanner([]ValuesFile{{Chart: api, Path: override}})
+	tests := []struct {
+		path string
+		want string
+		file string
+	}{
+		{filepath.Join(api, "values.yaml"), "nginx:1.25", override},
+		{filepath.Join(web, "values.yaml"), "nginx:1.20", filepath.Join(web, "values.yaml")},
+	}
+	for _, tt := range tests {
+		refs, err := s.Scan(tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(refs) != 1 || refs[0].Image != tt.want || refs[0].File != tt.file {
+			t.Errorf("Scan(%s) = %+v, want %s from %s", tt.path, refs, tt.want, tt.file)
+		}
+	}
+}
+
+func TestHelmValuesMatch(t *testing.T) {
+	tests := map[string]bool{
+		"values.yaml":          true,
+		"values.yml":           true,
+		"values-prod.yaml":     true,
+		"prod.values.yaml":     true,
+		"charts/a/values.yaml": true,
+		"Chart.yaml":           false,
+		"my-values.json":       false,
+	}
+	s := NewHelmValuesScanner(nil)
+	for path, want := range tests {
+		if got := s.Match(path); got != want {
+			t.Errorf("Match(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func referenceImages(refs []Reference) []string {
+	var images []string
+	for _, ref := range refs {
+		images = append(images, ref.Image)
+	}
+	return images
+}
@@ -0,0 +1,139 @@
+package scanner
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// podSpecPaths maps workload kinds to the path of their pod spec
+var podSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"PodTemplate":           {"template", "spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// containerLists are the pod spec fields that hold containers
+var containerLists = []string{"initContainers", "containers", "ephemeralContainers"}
+
+// KubernetesScanner finds container images in Kubernetes manifests and ECS
+// task definitions, and the Kubernetes version of kind and kubeadm cluster
+// configurations
+type KubernetesScanner struct{}
+
+// NewKubernetesScanner creates a Kubernetes manifest scanner
+func NewKubernetesScanner() *KubernetesScanner {
+	return &KubernetesScanner{}
+}
+
+// Name identifies the scanner
+func (s *KubernetesScanner) Name() string {
+	return "kubernetes"
+}
+
+// Match accepts any YAML or JSON file, since manifests have no naming
+// convention. Files that contain no workloads yield no references.
+func (s *KubernetesScanner) Match(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// MatchContent accepts files that look like Kubernetes objects, cluster
+// configurations or ECS task definitions
+func (s *KubernetesScanner) MatchContent(data []byte) bool {
+	if bytes.Contains(data, []byte("containerDefinitions")) {
+		return true
+	}
+	return bytes.Contains(data, []byte("apiVersion")) && bytes.Contains(data, []byte("kind"))
+}
+
+// Scan returns the image of every container, init container and ephemeral
+// container of the workloads in a multi-document manifest
+func (s *KubernetesScanner) Scan(path string) ([]Reference, error) {
+	docs, err := readYAML(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Reference
+	for _, doc := range docs {
+		refs = append(refs, scanKubernetesObject(doc, path)...)
+	}
+	return refs, nil
+}
+
+func scanKubernetesObject(obj *yaml.Node, path string) []Reference {
+	kind, _ := mappingString(obj, "kind")
+	if kind == "List" || strings.HasSuffix(kind, "List") {
+		var refs []Reference
+		for _, item := range sequenceItems(mappingValue(obj, "items")) {
+			refs = append(refs, scanKubernetesObject(item, path)...)
+		}
+		return refs
+	}
+
+	if refs, ok := scanClusterConfig(obj, kind, path); ok {
+		return refs
+	}
+	if refs, ok := scanECSTaskDefinition(obj, path); ok {
+		return refs
+	}
+
+	specPath, ok := podSpecPaths[kind]
+	if !ok {
+		return nil
+	}
+	podSpec := obj
+	for _, key := range specPath {
+		podSpec = mappingValue(podSpec, key)
+	}
+	if podSpec == nil {
+		return nil
+	}
+
+	metadata := mappingValue(obj, "metadata")
+	name, _ := mappingString(metadata, "name")
+	namespace, _ := mappingString(metadata, "namespace")
+
+	var refs []Reference
+	for _, list := range containerLists {
+		for _, container := range sequenceItems(mappingValue(podSpec, list)) {
+			imageName, node := mappingString(container, "image")
+			if node == nil {
+				continue
+			}
+			containerName, _ := mappingString(container, "name")
+
+			context := map[string]string{
+				"kind":      kind,
+				"name":      name,
+				"container": containerName,
+			}
+			if namespace != "" {
+				context["namespace"] = namespace
+			}
+			if list != "containers" {
+				context["containerType"] = strings.TrimSuffix(list, "s")
+			}
+
+			refs = append(refs, Reference{
+				Image:   imageName,
+				File:    path,
+				Line:    node.Line,
+				Context: context,
+			})
+		}
+	}
+	return refs
+}
@@ -0,0 +1,142 @@
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestKubernetesScan(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		want     []Reference
+	}{
+		{
+			name: "deployment",
+			manifest: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: migrate
+          image: flyway/flyway:9
+      containers:
+        - name: app
+          image: node:18
+`,
+			want: []Reference{
+				{Image: "flyway/flyway:9", Line: 11, Context: map[string]string{"kind": "Deployment", "name": "web", "namespace": "prod", "container": "migrate", "containerType": "initContainer"}},
+				{Image: "node:18", Line: 14, Context: map[string]string{"kind": "Deployment", "name": "web", "namespace": "prod", "container": "app"}},
+			},
+		},
+		{
+			name: "cronjob and pod in one file",
+			manifest: `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: dump
+              image: postgres:12
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  ephemeralContainers:
+    - name: shell
+      image: busybox:1.36
+`,
+			want: []Reference{
+				{Image: "postgres:12", Line: 12, Context: map[string]string{"kind": "CronJob", "name": "backup", "container": "dump"}},
+				{Image: "busybox:1.36", Line: 21, Context: map[string]string{"kind": "Pod", "name": "debug", "container": "shell", "containerType": "ephemeralContainer"}},
+			},
+		},
+		{
+			name: "list",
+			manifest: `apiVersion: v1
+kind: List
+items:
+  - apiVersion: apps/v1
+    kind: StatefulSet
+    metadata:
+      name: db
+    spec:
+      template:
+        spec:
+          containers:
+            - name: mysql
+              image: mysql:5.7
+`,
+			want: []Reference{
+				{Image: "mysql:5.7", Line: 13, Context: map[string]string{"kind": "StatefulSet", "name": "db", "container": "mysql"}},
+			},
+		},
+		{
+			name: "objects without containers",
+			manifest: `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+    - port: 80
+`,
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "manifest.yaml")
+			writeTestFile(t, path, tt.manifest)
+
+			got, err := NewKubernetesScanner().Scan(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range tt.want {
+				tt.want[i].File = path
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKubernetesMatch(t *testing.T) {
+	s := NewKubernetesScanner()
+	for path, want := range map[string]bool{
+		"deploy.yaml":  true,
+		"deploy.YML":   true,
+		"pod.json":     true,
+		"Dockerfile":   false,
+		"deploy.yaml~": false,
+	} {
+		if got := s.Match(path); got != want {
+			t.Errorf("Match(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	for content, want := range map[string]bool{
+		"apiVersion: v1\nkind: Pod\n":           true,
+		`{"containerDefinitions": []}`:          true,
+		"name: build\non: push\n":               false,
+		"apiVersion: v1\nmetadata: {name: x}\n": false,
+	} {
+		if got := s.MatchContent([]byte(content)); got != want {
+			t.Errorf("MatchContent(%q) = %v, want %v", content, got, want)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// KustomizeScanner reports the effective images of a kustomization, after
+// the images transformers of the overlay and its bases are applied
+type KustomizeScanner struct{}
+
+// NewKustomizeScanner creates a kustomization scanner
+func NewKustomizeScanner() *KustomizeScanner {
+	return &KustomizeScanner{}
+}
+
+// Name identifies the scanner
+func (s *KustomizeScanner) Name() string {
+	return "kustomize"
+}
+
+// Match recognizes kustomization.yaml, kustomization.yml and Kustomization
+func (s *KustomizeScanner) Match(path string) bool {
+	base := filepath.Base(path)
+	for _, name := range kustomizationFileNames {
+		if base == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Scan follows the resources, bases and components of the kustomization at
+// path and returns their container images as the overlay renders them, with
+// strategic merge patches and images overrides applied
+func (s *KustomizeScanner) Scan(path string) ([]Reference, error) {
+	build := &kustomizeBuild{visited: make(map[string]bool)}
+	refs, err := build.kustomization(path)
+	if err != nil {
+		return nil, err
+	}
+
+	overlay := filepath.Dir(path)
+	for i := range refs {
+		refs[i].Context["overlay"] = overlay
+	}
+	return refs, nil
+}
+
+// kustomizeBuild walks a tree of kustomizations
+type kustomizeBuild struct {
+	visited map[string]bool
+}
+
+// kustomization returns the images of the kustomization file at path
+func (b *kustomizeBuild) kustomization(path string) ([]Reference, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if b.visited[abs] {
+		return nil, fmt.Errorf("kustomization cycle at %s", path)
+	}
+	b.visited[abs] = true
+	defer delete(b.visited, abs)
+
+	docs, err := readYAML(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	doc := docs[0]
+	dir := filepath.Dir(path)
+
+	var refs []Reference
+	for _, resource := range localResources(doc, dir) {
+		resourceRefs, err := b.resource(resource)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, resourceRefs...)
+	}
+
+	// Kustomize applies patches before the images transformer
+	for _, patch := range strategicMergePatches(doc, path) {
+		refs = applyPatch(refs, patch)
+	}
+	for _, transform := range sequenceItems(mappingValue(doc, "images")) {
+		applyImageTransform(refs, transform, path)
+	}
+	if namespace, _ := mappingString(doc, "namespace"); namespace != "" {
+		for i := range refs {
+			refs[i].Context["namespace"] = namespace
+		}
+	}
+	addSources(refs, path)
+	return refs, nil
+}
+
+// resource returns the images of a resource, which is either a directory
+// holding a kustomization or a manifest file
+func (b *kustomizeBuild) resource(path string) ([]Reference, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		docs, err := readYAML(path)
+		if err != nil {
+			return nil, err
+		}
+		var refs []Reference
+		for _, doc := range docs {
+			refs = append(refs, scanKubernetesObject(doc, path)...)
+		}
+		return refs, nil
+	}
+
+	for _, name := range kustomizationFileNames {
+		kustomization := filepath.Join(path, name)
+		if _, err := os.Stat(kustomization); err == nil {
+			return b.kustomization(kustomization)
+		}
+	}
+	return nil, fmt.Errorf("no kustomization file in %s", path)
+}
+
+// localResources returns the paths of the resources, bases and components of
+// a kustomization that are not fetched from a remote URL
+func localResources(doc *yaml.Node, dir string) []string {
+	var paths []string
+	for _, field := range []string{"resources", "bases", "components"} {
+		for _, item := range sequenceItems(mappingValue(doc, field)) {
+			resource, ok := scalarValue(item)
+			if !ok || isRemoteResource(resource) {
+				continue
+			}
+			paths = append(paths, filepath.Join(dir, resource))
+		}
+	}
+	return paths
+}
+
+// strategicMergePatches returns the images set by the strategic merge
+// patches of a kustomization: the files or inline patches of
+// patchesStrategicMerge, and the path or patch of patches entries. JSON 6902
+// patches, which are lists of operations, are not applied. Inline patches
+// are located at their line in the kustomization.
+func strategicMergePatches(doc *yaml.Node, path string) [][]Reference {
+	dir := filepath.Dir(path)
+	var patches [][]Reference
+	add := func(file string, data []byte, line int) {
+		docs, err := parseYAML(data)
+		if err != nil {
+			return
+		}
+		var refs []Reference
+		for _, patch := range docs {
+			refs = append(refs, scanKubernetesObject(patch, file)...)
+		}
+		if line > 0 {
+			for i := range refs {
+				refs[i].Line = line
+			}
+		}
+		patches = append(patches, refs)
+	}
+	addFile := func(name string) {
+		file := filepath.Join(dir, name)
+		if data, err := os.ReadFile(file); err == nil {
+			add(file, data, 0)
+		}
+	}
+
+	for _, item := range sequenceItems(mappingValue(doc, "patchesStrategicMerge")) {
+		patch, ok := scalarValue(item)
+		switch {
+		case !ok:
+		case strings.Contains(patch, "\n"):
+			add(path, []byte(patch), item.Line)
+		default:
+			addFile(patch)
+		}
+	}
+	for _, item := range sequenceItems(mappingValue(doc, "patches")) {
+		if file, _ := mappingString(item, "path"); file != "" {
+			addFile(file)
+		} else if patch, node := mappingString(item, "patch"); node != nil {
+			add(path, []byte(patch), node.Line)
+		}
+	}
+	return patches
+}
+
+// applyPatch replaces the image of the containers a patch sets, matched by
+// kind, name and container, and adds the containers it introduces
+func applyPatch(refs []Reference, patch []Reference) []Reference {
+	for _, patched := range patch {
+		found := false
+		for i := range refs {
+			if !samePatchTarget(refs[i].Context, patched.Context) {
+				continue
+			}
+			found = true
+			refs[i].Image = patched.Image
+			refs[i].Raw = ""
+			// The manifest still declares the container
+			manifest := refs[i].File
+			refs[i].File = patched.File
+			refs[i].Line = patched.Line
+			addSources(refs[i:i+1], manifest)
+		}
+		if !found {
+			refs = append(refs, patched)
+		}
+	}
+	return refs
+}
+
+// samePatchTarget reports whether two container contexts name the same
+// container of the same object
+func samePatchTarget(a, b map[string]string) bool {
+	for _, key := range []string{"kind", "name", "container", "containerType"} {
+		if a[key] != b[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// kustomizationIncludes returns the files that the kustomization at path
+// reads: its manifests and patches, and the kustomization files of its bases
+// and components
+func kustomizationIncludes(path string) []string {
+	docs, err := readYAML(path)
+	if err != nil || len(docs) == 0 {
+		return nil
+	}
+	doc := docs[0]
+	dir := filepath.Dir(path)
+
+	var files []string
+	for _, resource := range localResources(doc, dir) {
+		info, err := os.Stat(resource)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			files = append(files, resource)
+			continue
+		}
+		for _, name := range kustomizationFileNames {
+			if kustomization := filepath.Join(resource, name); FileExists(kustomization) {
+				files = append(files, kustomization)
+				break
+			}
+		}
+	}
+	for _, item := range sequenceItems(mappingValue(doc, "patchesStrategicMerge")) {
+		if patch, ok := scalarValue(item); ok {
+			files = append(files, filepath.Join(dir, patch))
+		}
+	}
+	for _, item := range sequenceItems(mappingValue(doc, "patches")) {
+		if patch, _ := mappingString(item, "path"); patch != "" {
+			files = append(files, filepath.Join(dir, patch))
+		}
+	}
+	return files
+}
+
+// applyImageTransform applies one entry of an images list (name, newName,
+// newTag, digest) to the matching references. A transformed reference
+// points at the kustomization line that set its version. Kustomize renders
+// name:newTag@digest when both are set, but the tag carries the version, so
+// the digest is only kept without a tag.
+func applyImageTransform(refs []Reference, transform *yaml.Node, path string) {
+	name, _ := mappingString(transform, "name")
+	if name == "" {
+		return
+	}
+	newName, newNameNode := mappingString(transform, "newName")
+	newTag, newTagNode := mappingString(transform, "newTag")
+	digest, digestNode := mappingString(transform, "digest")
+
+	for i := range refs {
+		repository, tag, refDigest := splitImage(refs[i].Image)
+		if repository != name {
+			continue
+		}
+
+		line := transform.Line
+		if newNameNode != nil {
+			repository = newName
+			line = newNameNode.Line
+		}
+		switch {
+		case newTagNode != nil:
+			tag, refDigest = newTag, ""
+			line = newTagNode.Line
+		case digestNode != nil:
+			tag, refDigest = "", digest
+			line = digestNode.Line
+		}
+
+		image := imageReference("", repository, tag, refDigest)
+
+		if refs[i].Raw == "" {
+			refs[i].Raw = refs[i].Image
+		}
+		refs[i].Image = image
+		manifest := refs[i].File
+		refs[i].File = path
+		refs[i].Line = line
+		addSources(refs[i:i+1], manifest)
+	}
+}
+
+// splitImage splits a reference into repository, tag and digest
+func splitImage(ref string) (repository, tag, digest string) {
+	repository, digest, _ = strings.Cut(ref, "@")
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+	return repository, tag, digest
+}
+
+// isRemoteResource reports whether a resource is fetched from a remote URL
+func isRemoteResource(resource string) bool {
+	return strings.Contains(resource, "://") ||
+		strings.HasPrefix(resource, "github.com/") ||
+		strings.HasPrefix(resource, "git@")
+}
@@ -0,0 +1,169 @@
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestApplyImageTransform(t *testing.T) {
+	tests := []struct {
+		name      string
+		image     string
+		transform string
+		want      string
+		wantLine  int
+	}{
+		{name: "new tag", image: "nginx:1.20", transform: "name: nginx\nnewTag: \"1.25\"\n", want: "nginx:1.25", wantLine: 2},
+		{name: "new name", image: "nginx:1.20", transform: "name: nginx\nnewName: registry.example.com/nginx\n", want: "registry.example.com/nginx:1.20", wantLine: 2},
+		{name: "new name and tag", image: "nginx", transform: "name: nginx\nnewName: bitnami/nginx\nnewTag: \"1.25\"\n", want: "bitnami/nginx:1.25", wantLine: 3},
+		{name: "digest", image: "nginx:1.20", transform: "name: nginx\ndigest: sha256:abc\n", want: "nginx@sha256:abc", wantLine: 2},
+		{name: "tag and digest", image: "nginx:1.20", transform: "name: nginx\nnewTag: \"1.25\"\ndigest: sha256:abc\n", want: "nginx:1.25", wantLine: 2},
+		{name: "digest and tag", image: "nginx", transform: "name: nginx\ndigest: sha256:abc\nnewTag: \"1.25\"\n", want: "nginx:1.25", wantLine: 3},
+		{name: "tag replaces digest", image: "nginx@sha256:abc", transform: "name: nginx\nnewTag: \"1.25\"\n", want: "nginx:1.25", wantLine: 2},
+		{name: "other image", image: "redis:7", transform: "name: nginx\nnewTag: \"1.25\"\n", want: "redis:7", wantLine: 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc yaml.Node
+			if err := yaml.Unmarshal([]byte(tt.transform), &doc); err != nil {
+				t.Fatal(err)
+			}
+			refs := []Reference{{Image: tt.image, File: "deploy.yaml", Line: 9}}
+			applyImageTransform(refs, doc.Content[0], "kustomization.yaml")
+			if refs[0].Image != tt.want || refs[0].Line != tt.wantLine {
+				t.Errorf("image %s at line %d, want %s at line %d", refs[0].Image, refs[0].Line, tt.want, tt.wantLine)
+			}
+		})
+	}
+}
+
+func TestKustomizeScan(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "base", "kustomization.yaml"), `resources:
+  - deploy.yaml
+  - https://github.com/example/remote//deploy
+images:
+  - name: redis
+    newTag: "6.2"
+`)
+	writeTestFile(t, filepath.Join(dir, "base", "deploy.yaml"), `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.20
+        - name: cache
+          image: redis:5.0
+`)
+	overlay := filepath.Join(dir, "overlays", "prod")
+	path := filepath.Join(overlay, "kustomization.yaml")
+	writeTestFile(t, path, `namespace: prod
+resources:
+  - ../../base
+images:
+  - name: nginx
+    newTag: "1.25"
+`)
+
+	refs, err := NewKustomizeScanner().Scan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := filepath.Join(dir, "base", "kustomization.yaml")
+	manifest := filepath.Join(dir, "base", "deploy.yaml")
+	want := []Reference{
+		{Image: "nginx:1.25", Raw: "nginx:1.20", File: path, Line: 6, Context: map[string]string{"kind": "Deployment", "name": "web", "container": "web", "namespace": "prod", "overlay": overlay}, Sources: []string{base, manifest}},
+		{Image: "redis:6.2", Raw: "redis:5.0", File: base, Line: 6, Context: map[string]string{"kind": "Deployment", "name": "web", "container": "cache", "namespace": "prod", "overlay": overlay}, Sources: []string{manifest, path}},
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("Scan() = %+v, want %+v", refs, want)
+	}
+}
+
+func TestKustomizeCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "a", "kustomization.yaml"), "resources:\n  - ../b\n")
+	writeTestFile(t, filepath.Join(dir, "b", "kustomization.yaml"), "resources:\n  - ../a\n")
+	if _, err := NewKustomizeScanner().Scan(filepath.Join(dir, "a", "kustomization.yaml")); err == nil {
+		t.Error("Scan() of a kustomization cycle succeeded, want an error")
+	}
+}
+
+func TestKustomizePatches(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "base", "kustomization.yaml"), "resources:\n  - deploy.yaml\n")
+	writeTestFile(t, filepath.Join(dir, "base", "deploy.yaml"), `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.20
+        - name: cache
+          image: redis:6.2
+`)
+	overlay := filepath.Join(dir, "overlay")
+	patch := filepath.Join(overlay, "cache.yaml")
+	writeTestFile(t, patch, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: cache
+          image: redis:5.0
+        - name: metrics
+          image: prom/statsd-exporter:v0.22.0
+`)
+	path := filepath.Join(overlay, "kustomization.yaml")
+	writeTestFile(t, path, `resources:
+  - ../base
+patchesStrategicMerge:
+  - cache.yaml
+patches:
+  - patch: |-
+      apiVersion: apps/v1
+      kind: Deployment
+      metadata:
+        name: web
+      spec:
+        template:
+          spec:
+            containers:
+              - name: web
+                image: nginx:1.18
+images:
+  - name: prom/statsd-exporter
+    newTag: v0.26.0
+`)
+
+	refs, err := NewKustomizeScanner().Scan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	context := func(container string) map[string]string {
+		return map[string]string{"kind": "Deployment", "name": "web", "container": container, "overlay": overlay}
+	}
+	base := filepath.Join(dir, "base", "kustomization.yaml")
+	manifest := filepath.Join(dir, "base", "deploy.yaml")
+	want := []Reference{
+		{Image: "nginx:1.18", File: path, Line: 6, Context: context("web"), Sources: []string{base, manifest}},
+		{Image: "redis:5.0", File: patch, Line: 10, Context: context("cache"), Sources: []string{base, manifest, path}},
+		{Image: "prom/statsd-exporter:v0.26.0", Raw: "prom/statsd-exporter:v0.22.0", File: path, Line: 19, Context: context("metrics"), Sources: []string{patch}},
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("Scan() = %+v, want %+v", refs, want)
+	}
+}
@@ -0,0 +1,74 @@
+package scanner
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// NomadScanner finds container images in Nomad job specifications
+type NomadScanner struct{}
+
+// NewNomadScanner creates a Nomad job file scanner
+func NewNomadScanner() *NomadScanner {
+	return &NomadScanner{}
+}
+
+// Name identifies the scanner
+func (s *NomadScanner) Name() string {
+	return "nomad"
+}
+
+// Match recognizes *.nomad and *.nomad.hcl files
+func (s *NomadScanner) Match(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	return strings.HasSuffix(name, ".nomad") || strings.HasSuffix(name, ".nomad.hcl")
+}
+
+// Scan returns the config.image of every task of every group of the jobs in
+// the file. Variable defaults and locals declared in the file are resolved.
+func (s *NomadScanner) Scan(path string) ([]Reference, error) {
+	file, err := parseHCL(path)
+	if err != nil {
+		return nil, err
+	}
+	body := file.Body.(*hclsyntax.Body)
+	m := newHCLModule([]*hclsyntax.Body{body}, nil)
+
+	var refs []Reference
+	for _, job := range labeledBlocks(body, "job") {
+		for _, group := range labeledBlocks(job.Body, "group") {
+			for _, task := range labeledBlocks(group.Body, "task") {
+				for _, config := range task.Body.Blocks {
+					if config.Type != "config" {
+						continue
+					}
+					attr := config.Body.Attributes["image"]
+					if attr == nil {
+						continue
+					}
+					context := map[string]string{
+						"job":   job.Labels[0],
+						"group": group.Labels[0],
+						"task":  task.Labels[0],
+					}
+					refs = append(refs, m.imageReference(attr.Expr, file.Bytes, path, context))
+				}
+			}
+		}
+	}
+	return refs, nil
+}
+
+// labeledBlocks returns the blocks of body of the given type that have a
+// single label
+func labeledBlocks(body *hclsyntax.Body, blockType string) []*hclsyntax.Block {
+	var blocks []*hclsyntax.Block
+	for _, block := range body.Blocks {
+		if block.Type == blockType && len(block.Labels) == 1 {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNomadScan(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "web.nomad.hcl")
+	writeTestFile(t, path, `variable "redis_version" {
+  default = "6.2"
+}
+
+variable "app_tag" {
+  type = string
+}
+
+locals {
+  registry = "registry.example.com"
+}
+
+job "web" {
+  group "cache" {
+    task "redis" {
+      driver = "docker"
+      config {
+        image = "redis:${var.redis_version}"
+      }
+    }
+  }
+
+  group "app" {
+    task "server" {
+      driver = "docker"
+      config {
+        image = "${local.registry}/app:${var.app_tag}"
+      }
+    }
+    task "proxy" {
+      driver = "podman"
+      config {
+        image = "nginx:1.20"
+      }
+    }
+    task "batch" {
+      driver = "exec"
+      config {
+        command = "/bin/true"
+      }
+    }
+  }
+}
+`)
+
+	got, err := NewNomadScanner().Scan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Reference{
+		{Image: "redis:6.2", Raw: "redis:${var.redis_version}", File: path, Line: 18, Context: map[string]string{"job": "web", "group": "cache", "task": "redis"}},
+		{Image: "${local.registry}/app:${var.app_tag}", File: path, Line: 27, Context: map[string]string{"job": "web", "group": "app", "task": "server"}, Unresolved: []string{"var.app_tag"}},
+		{Image: "nginx:1.20", File: path, Line: 33, Context: map[string]string{"job": "web", "group": "app", "task": "proxy"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Scan() = %+v, want %+v", got, want)
+	}
+}
+
+func TestNomadMatch(t *testing.T) {
+	s := NewNomadScanner()
+	for path, want := range map[string]bool{
+		"web.nomad":          true,
+		"jobs/web.nomad.hcl": true,
+		"main.tf":            false,
+		"web.hcl":            false,
+	} {
+		if got := s.Match(path); got != want {
+			t.Errorf("Match(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// leadingVersion matches the numeric version at the start of a pin
+var leadingVersion = regexp.MustCompile(`^v?(\d+(?:\.\d+)*)`)
+
+// anyVersion matches the first numeric version in a range expression
+var anyVersion = regexp.MustCompile(`\d+(?:\.\d+)*`)
+
+// nodeLTSCodenames maps Node.js LTS codenames, as in "lts/hydrogen", to
+// their major version
+var nodeLTSCodenames = map[string]string{
+	"argon":    "4",
+	"boron":    "6",
+	"carbon":   "8",
+	"dubnium":  "10",
+	"erbium":   "12",
+	"fermium":  "14",
+	"gallium":  "16",
+	"hydrogen": "18",
+	"iron":     "20",
+	"jod":      "22",
+}
+
+// toolVersionsProducts maps asdf/mise tool names to endoflife.date products
+var toolVersionsProducts = map[string]string{
+	"nodejs":    "nodejs",
+	"node":      "nodejs",
+	"python":    "python",
+	"ruby":      "ruby",
+	"golang":    "go",
+	"go":        "go",
+	"php":       "php",
+	"elixir":    "elixir",
+	"erlang":    "erlang",
+	"dotnet":    "dotnet",
+	"deno":      "deno",
+	"bun":       "bun",
+	"terraform": "terraform",
+	"kubectl":   "kubernetes",
+	"postgres":  "postgresql",
+	"redis":     "redis",
+}
+
+// runtimeFiles maps the version files the scanner reads to their parser
+var runtimeFiles = map[string]func(path string) ([]Reference, error){
+	".nvmrc":          scanNodeVersionFile,
+	".node-version":   scanNodeVersionFile,
+	".python-version": scanPythonVersionFile,
+	".ruby-version":   scanRubyVersionFile,
+	".tool-versions":  scanToolVersions,
+	"go.mod":          scanGoMod,
+	"package.json":    scanPackageJSON,
+}
+
+// RuntimeScanner finds language runtime versions pinned in version files
+type RuntimeScanner struct{}
+
+// NewRuntimeScanner creates a runtime version file scanner
+func NewRuntimeScanner() *RuntimeScanner {
+	return &RuntimeScanner{}
+}
+
+// Name identifies the scanner
+func (s *RuntimeScanner) Name() string {
+	return "runtime"
+}
+
+// Match recognizes .nvmrc, .node-version, .python-version, .ruby-version,
+// .tool-versions, go.mod and package.json
+func (s *RuntimeScanner) Match(path string) bool {
+	_, ok := runtimeFiles[filepath.Base(path)]
+	return ok
+}
+
+// Scan returns one product reference per pinned runtime version
+func (s *RuntimeScanner) Scan(path string) ([]Reference, error) {
+	scan, ok := runtimeFiles[filepath.Base(path)]
+	if !ok {
+		return nil, ErrUnsupported
+	}
+	return scan(path)
+}
+
+// runtimeReference builds the product reference of a pinned version
+func runtimeReference(tool, product, version, path string, line int, context map[string]string) Reference {
+	return Reference{
+		Product: product,
+		Version: version,
+		Subject: fmt.Sprintf("%s version %s pinned in %s", tool, version, filepath.Base(path)),
+		File:    path,
+		Line:    line,
+		Context: context,
+	}
+}
+
+// versionLines calls fn with every non-empty line of path that is not a
+// comment
+func versionLines(path string, fn func(line string, lineNo int)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line != "" {
+			fn(line, lineNo)
+		}
+	}
+	return sc.Err()
+}
+
+// scanNodeVersionFile reads .nvmrc and .node-version, which hold a version
+// such as v18.17.0 or an alias such as lts/hydrogen
+func scanNodeVersionFile(path string) ([]Reference, error) {
+	var refs []Reference
+	err := versionLines(path, func(line string, lineNo int) {
+		if len(refs) > 0 {
+			return
+		}
+		version := ""
+		if codename, ok := strings.CutPrefix(strings.ToLower(line), "lts/"); ok {
+			version = nodeLTSCodenames[codename]
+		} else if m := leadingVersion.FindStringSubmatch(line); m != nil {
+			version = m[1]
+		}
+		if version != "" {
+			refs = append(refs, runtimeReference("node", "nodejs", version, path, lineNo, nil))
+		}
+	})
+	return refs, err
+}
+
+// scanPythonVersionFile reads .python-version, which lists one or more
+// pyenv versions. Non-CPython entries such as pypy3.9 are skipped.
+func scanPythonVersionFile(path string) ([]Reference, error) {
+	var refs []Reference
+	err := versionLines(path, func(line string, lineNo int) {
+		if m := leadingVersion.FindStringSubmatch(line); m != nil {
+			refs = append(refs, runtimeReference("python", "python", m[1], path, lineNo, nil))
+		}
+	})
+	return refs, err
+}
+
+// scanRubyVersionFile reads .ruby-version, e.g. 3.2.2 or ruby-3.2.2
+func scanRubyVersionFile(path string) ([]Reference, error) {
+	var refs []Reference
+	err := versionLines(path, func(line string, lineNo int) {
+		if len(refs) > 0 {
+			return
+		}
+		if m := leadingVersion.FindStringSubmatch(strings.TrimPrefix(line, "ruby-")); m != nil {
+			refs = append(refs, runtimeReference("ruby", "ruby", m[1], path, lineNo, nil))
+		}
+	})
+	return refs, err
+}
+
+// scanToolVersions reads an asdf/mise .tool-versions file. Only the first
+// version of a tool is used; the others are fallbacks.
+func scanToolVersions(path string) ([]Reference, error) {
+	var refs []Reference
+	err := versionLines(path, func(line string, lineNo int) {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return
+		}
+		product, ok := toolVersionsProducts[fields[0]]
+		if !ok {
+			return
+		}
+		if m := leadingVersion.FindStringSubmatch(fields[1]); m != nil {
+			refs = append(refs, runtimeReference(fields[0], product, m[1], path, lineNo, nil))
+		}
+	})
+	return refs, err
+}
+
+// scanGoMod reads the go and toolchain directives of a go.mod file
+func scanGoMod(path string) ([]Reference, error) {
+	var refs []Reference
+	err := versionLines(path, func(line string, lineNo int) {
+		fields := strings.Fields(strings.SplitN(line, "//", 2)[0])
+		if len(fields) != 2 {
+			return
+		}
+		var version string
+		switch fields[0] {
+		case "go":
+			version = fields[1]
+		case "toolchain":
+			version = strings.TrimPrefix(fields[1], "go")
+		default:
+			return
+		}
+		if m := leadingVersion.FindStringSubmatch(version); m != nil {
+			refs = append(refs, runtimeReference("go", "go", m[1], path, lineNo, map[string]string{"directive": fields[0]}))
+		}
+	})
+	return refs, err
+}
+
+// scanPackageJSON reads engines.node and volta.node from package.json. For a
+// range such as ">=18 <21" the lowest version is checked, since that is the
+// oldest runtime the package claims to support.
+func scanPackageJSON(path string) ([]Reference, error) {
+	docs, err := readYAML(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, nil
+	}
+
+	var refs []Reference
+	for _, field := range []string{"engines", "volta"} {
+		constraint, node := mappingString(mappingValue(docs[0], field), "node")
+		if node == nil {
+			continue
+		}
+		version := lowestVersion(constraint)
+		if version == "" {
+			continue
+		}
+		refs = append(refs, runtimeReference("node", "nodejs", version, path, node.Line, map[string]string{"field": field + ".node"}))
+	}
+	return refs, nil
+}
+
+// lowestVersion returns the lowest version mentioned in a semver range
+func lowestVersion(constraint string) string {
+	var lowest string
+	var lowestParts []int
+	for _, v := range anyVersion.FindAllString(constraint, -1) {
+		parts := versionParts(v)
+		if lowest == "" || compareParts(parts, lowestParts) < 0 {
+			lowest, lowestParts = v, parts
+		}
+	}
+	return lowest
+}
+
+func versionParts(v string) []int {
+	fields := strings.Split(v, ".")
+	parts := make([]int, len(fields))
+	for i, f := range fields {
+		fmt.Sscanf(f, "%d", &parts[i])
+	}
+	return parts
+}
+
+func compareParts(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
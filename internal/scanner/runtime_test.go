@@ -0,0 +1,125 @@
+package scanner
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRuntimeScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []string
+	}{
+		{name: "nvmrc", file: ".nvmrc", content: "v18.17.0\n", want: []string{"nodejs 18.17.0 line 1"}},
+		{name: "nvmrc codename", file: ".nvmrc", content: "# pinned\nlts/Gallium\n", want: []string{"nodejs 16 line 2"}},
+		{name: "nvmrc alias", file: ".nvmrc", content: "lts/*\n", want: nil},
+		{name: "node-version", file: ".node-version", content: "20\n", want: []string{"nodejs 20 line 1"}},
+		{name: "python-version", file: ".python-version", content: "3.8.18\npypy3.9\n3.12\n", want: []string{"python 3.8.18 line 1", "python 3.12 line 3"}},
+		{name: "ruby-version", file: ".ruby-version", content: "ruby-2.7.8\n", want: []string{"ruby 2.7.8 line 1"}},
+		{
+			name:    "tool-versions",
+			file:    ".tool-versions",
+			content: "nodejs 16.20.0 18.0.0\npython 3.11.4 # main\ngolang 1.20\nshellcheck 0.9.0\nkubectl v1.25.0\n",
+			want:    []string{"nodejs 16.20.0 line 1", "python 3.11.4 line 2", "go 1.20 line 3", "kubernetes 1.25.0 line 5"},
+		},
+		{
+			name:    "go.mod",
+			file:    "go.mod",
+			content: "module example.com/app\n\ngo 1.19 // minimum\n\ntoolchain go1.21.5\n\nrequire golang.org/x/text v0.3.8\n",
+			want:    []string{"go 1.19 line 3", "go 1.21.5 line 5"},
+		},
+		{
+			name: "package.json",
+			file: "package.json",
+			content: `{
+  "name": "app",
+  "engines": {"node": ">=14.17 <21 || 16.x"},
+  "volta": {"node": "20.10.0"}
+}
+`,
+			want: []string{"nodejs 14.17 line 3", "nodejs 20.10.0 line 4"},
+		},
+		{name: "package.json without engines", file: "package.json", content: `{"name": "app"}`, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			writeTestFile(t, path, tt.content)
+
+			refs, err := NewRuntimeScanner().Scan(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, ref := range refs {
+				if ref.File != path || ref.Image != "" {
+					t.Errorf("reference %+v, want a product reference in %s", ref, path)
+				}
+				got = append(got, fmt.Sprintf("%s %s line %d", ref.Product, ref.Version, ref.Line))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuntimeReferenceContext(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "go.mod")
+	writeTestFile(t, path, "module m\n\ngo 1.21\n")
+	refs, err := NewRuntimeScanner().Scan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Reference{{
+		Product: "go",
+		Version: "1.21",
+		Subject: "go version 1.21 pinned in go.mod",
+		File:    path,
+		Line:    3,
+		Context: map[string]string{"directive": "go"},
+	}}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("Scan() = %+v, want %+v", refs, want)
+	}
+}
+
+func TestLowestVersion(t *testing.T) {
+	tests := map[string]string{
+		">=18":             "18",
+		"^16.14.0":         "16.14.0",
+		">=14 <21":         "14",
+		"18.x || 16.x":     "16",
+		"16.14 || 16.2":    "16.2",
+		">=16.0.0 <16.0.1": "16.0.0",
+		"*":                "",
+	}
+	for constraint, want := range tests {
+		if got := lowestVersion(constraint); got != want {
+			t.Errorf("lowestVersion(%q) = %q, want %q", constraint, got, want)
+		}
+	}
+}
+
+func TestRuntimeMatch(t *testing.T) {
+	s := NewRuntimeScanner()
+	for path, want := range map[string]bool{
+		".nvmrc":                true,
+		"web/.node-version":     true,
+		".python-version":       true,
+		".tool-versions":        true,
+		"go.mod":                true,
+		"go.sum":                false,
+		"frontend/package.json": true,
+		"package-lock.json":     false,
+	} {
+		if got := s.Match(path); got != want {
+			t.Errorf("Match(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
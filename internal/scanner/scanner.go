@@ -0,0 +1,147 @@
+package scanner
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+)
+
+// ErrUnsupported is returned for files no scanner recognizes
+var ErrUnsupported = errors.New("unsupported file type")
+
+// Reference is an image reference, or a product version such as a runner
+// OS, found in a file
+type Reference struct {
+	// Image is the reference after variable substitution, or as written
+	// when some variables could not be resolved. It is empty for product
+	// references.
+	Image string `json:"image,omitempty"`
+	// Product and Version identify an endoflife.date release for references
+	// that are not images, and Subject describes it, e.g. "runner ubuntu-20.04"
+	Product string `json:"product,omitempty"`
+	Version string `json:"version,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	// Raw is the reference as written, when it differs from Image
+	Raw     string            `json:"raw,omitempty"`
+	File    string            `json:"file"`
+	Line    int               `json:"line,omitempty"`
+	Context map[string]string `json:"context,omitempty"`
+	// Unresolved lists the variables Image depends on that are not set
+	Unresolved []string `json:"unresolved,omitempty"`
+	// Sources are the other files the reference was resolved from, such as
+	// a .env file or the kustomization that set its tag
+	Sources []string `json:"sources,omitempty"`
+}
+
+// Scanner extracts image references from one kind of file
+type Scanner interface {
+	// Name identifies the kind of file, e.g. "dockerfile"
+	Name() string
+	// Match reports whether the scanner handles the file at path, based on its name
+	Match(path string) bool
+	// Scan returns the image references found in the file at path
+	Scan(path string) ([]Reference, error)
+}
+
+// Options configures the file scanners
+type Options struct {
+	// BuildArgs override Dockerfile ARG defaults
+	BuildArgs map[string]string
+	// ValuesFiles are layered over the values.yaml of their chart, like
+	// helm -f
+	ValuesFiles []ValuesFile
+}
+
+// Set dispatches files to the scanner that handles them
+type Set struct {
+	scanners []Scanner
+}
+
+// NewSet creates a set with every available scanner
+func NewSet(opts Options) *Set {
+	dockerfiles := NewDockerfileScanner(opts.BuildArgs)
+	compose := NewComposeScanner(dockerfiles)
+	return &Set{
+		scanners: []Scanner{
+			dockerfiles,
+			NewRuntimeScanner(),
+			NewDevcontainerScanner(dockerfiles, compose),
+			NewGitHubActionsScanner(),
+			NewGitLabCIScanner(),
+			compose,
+			NewServerlessScanner(),
+			NewTerraformScanner(),
+			NewNomadScanner(),
+			NewSystemdScanner(),
+			NewECSScanner(),
+			NewHelmValuesScanner(opts.ValuesFiles),
+			NewKustomizeScanner(),
+			// Generic YAML scanners come last so that named files are
+			// handled by their own scanner first
+			NewKubernetesScanner(),
+		},
+	}
+}
+
+// Detect returns the scanner that handles path, or nil
+func (s *Set) Detect(path string) Scanner {
+	for _, sc := range s.scanners {
+		if sc.Match(path) {
+			return sc
+		}
+	}
+	return nil
+}
+
+// ScanFile scans path with the scanner that handles it. Errors do not repeat
+// the path, callers report it alongside.
+func (s *Set) ScanFile(path string) ([]Reference, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			return nil, pathErr.Err
+		}
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, errors.New("is a directory")
+	}
+
+	sc := s.Detect(path)
+	if sc == nil {
+		return nil, ErrUnsupported
+	}
+	return sc.Scan(path)
+}
+
+// addSources records that refs were resolved from the given files, besides
+// their own
+func addSources(refs []Reference, sources ...string) {
+	for i := range refs {
+		for _, source := range sources {
+			if source != refs[i].File && !slices.Contains(refs[i].Sources, source) {
+				refs[i].Sources = append(refs[i].Sources, source)
+			}
+		}
+	}
+}
+
+// newReference expands raw with x and builds a reference. When variables are
+// missing, the reference keeps the raw image and lists them as unresolved.
+func newReference(raw string, x *expander, path string, line int, context map[string]string) Reference {
+	ref := Reference{
+		Image:   x.expand(raw),
+		File:    path,
+		Line:    line,
+		Context: context,
+	}
+	if len(x.unresolved) > 0 {
+		ref.Image = raw
+		ref.Unresolved = x.unresolved
+	} else if ref.Image != raw {
+		ref.Raw = raw
+	}
+	return ref
+}
@@ -0,0 +1,204 @@
+package scanner
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// lambdaProduct is the endoflife.date product whose cycles are Lambda
+// runtime identifiers such as python3.8 or nodejs16.x
+const lambdaProduct = "aws-lambda"
+
+var serverlessTemplateName = regexp.MustCompile(`^(template|serverless|.+\.template|.+\.cfn|.+\.sam)(\.(ya?ml|json))?$`)
+
+// lambdaFunctionTypes are the CloudFormation resource types with a Runtime
+var lambdaFunctionTypes = map[string]bool{
+	"AWS::Serverless::Function": true,
+	"AWS::Lambda::Function":     true,
+}
+
+// ServerlessScanner finds Lambda runtimes in AWS SAM and CloudFormation
+// templates and Serverless Framework configurations
+type ServerlessScanner struct{}
+
+// NewServerlessScanner creates a serverless template scanner
+func NewServerlessScanner() *ServerlessScanner {
+	return &ServerlessScanner{}
+}
+
+// Name identifies the scanner
+func (s *ServerlessScanner) Name() string {
+	return "serverless"
+}
+
+// Match recognizes template.yaml, serverless.yml, *.template and *.cfn.yaml
+// files in YAML or JSON
+func (s *ServerlessScanner) Match(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	if !serverlessTemplateName.MatchString(name) {
+		return false
+	}
+	// A bare "template" or "serverless" without extension is not a template
+	return strings.Contains(name, ".")
+}
+
+// MatchContent accepts CloudFormation and SAM templates, which have a
+// format version or AWS:: resources, and Serverless Framework
+// configurations, which have a provider with functions or a runtime. Names
+// such as *.template are also used for e-mail and other templates.
+func (s *ServerlessScanner) MatchContent(data []byte) bool {
+	switch {
+	case bytes.Contains(data, []byte("AWSTemplateFormatVersion")):
+		return true
+	case bytes.Contains(data, []byte("Resources")) && bytes.Contains(data, []byte("AWS::")):
+		return true
+	default:
+		return bytes.Contains(data, []byte("provider")) &&
+			(bytes.Contains(data, []byte("functions")) || bytes.Contains(data, []byte("runtime")))
+	}
+}
+
+// Scan returns the runtime of every Lambda function, each checked against
+// the aws-lambda product
+func (s *ServerlessScanner) Scan(path string) ([]Reference, error) {
+	docs, err := readYAML(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []Reference
+	for _, doc := range docs {
+		if mappingValue(doc, "Resources") != nil {
+			refs = append(refs, cloudFormationRuntimes(doc, path)...)
+		}
+		if mappingValue(doc, "provider") != nil || mappingValue(doc, "functions") != nil {
+			refs = append(refs, serverlessFrameworkRuntimes(doc, path)...)
+		}
+	}
+	return refs, nil
+}
+
+// cloudFormationRuntimes reads the Runtime of Lambda function resources,
+// falling back to the SAM Globals section and resolving !Ref parameters
+func cloudFormationRuntimes(doc *yaml.Node, path string) []Reference {
+	parameters := mappingValue(doc, "Parameters")
+	globalRuntime := mappingValue(mappingValue(mappingValue(doc, "Globals"), "Function"), "Runtime")
+
+	var refs []Reference
+	for _, resource := range mappingEntries(mappingValue(doc, "Resources")) {
+		resourceType, _ := mappingString(resource.value, "Type")
+		if !lambdaFunctionTypes[resourceType] {
+			continue
+		}
+
+		properties := mappingValue(resource.value, "Properties")
+		if packageType, _ := mappingString(properties, "PackageType"); packageType == "Image" {
+			// Container image functions have no runtime
+			continue
+		}
+		runtime := mappingValue(properties, "Runtime")
+		if runtime == nil && resourceType == "AWS::Serverless::Function" {
+			runtime = globalRuntime
+		}
+		if runtime == nil {
+			continue
+		}
+
+		value, node, unresolved := cloudFormationString(runtime, parameters)
+		context := map[string]string{"function": resource.key.Value, "type": resourceType}
+		refs = append(refs, lambdaReference(value, unresolved, path, node.Line, context))
+	}
+	return refs
+}
+
+// cloudFormationString resolves a scalar, or a Ref to a parameter default
+// in either the !Ref short form or the {"Ref": name} JSON form
+func cloudFormationString(n, parameters *yaml.Node) (string, *yaml.Node, string) {
+	n = resolve(n)
+	customTag := strings.HasPrefix(n.Tag, "!") && !strings.HasPrefix(n.Tag, "!!")
+
+	var name string
+	switch {
+	case n.Tag == "!Ref":
+		name = n.Value
+	case customTag:
+		// Other intrinsic functions such as !Sub can't be evaluated
+		return "", n, n.Tag
+	default:
+		if ref, node := mappingString(n, "Ref"); node != nil {
+			name = ref
+		} else if value, ok := scalarValue(n); ok {
+			return value, n, ""
+		} else if entries := mappingEntries(n); len(entries) > 0 {
+			return "", n, entries[0].key.Value
+		} else {
+			return "", n, "Runtime"
+		}
+	}
+
+	if value, node := mappingString(mappingValue(parameters, name), "Default"); node != nil {
+		return value, n, ""
+	}
+	return "", n, name
+}
+
+// serverlessFrameworkRuntimes reads provider.runtime and the runtime of each
+// function in a serverless.yml
+func serverlessFrameworkRuntimes(doc *yaml.Node, path string) []Reference {
+	providerRuntime, providerNode := mappingString(mappingValue(doc, "provider"), "runtime")
+
+	var refs []Reference
+	functions := mappingEntries(mappingValue(doc, "functions"))
+	for _, fn := range functions {
+		if _, image := mappingString(fn.value, "image"); image != nil {
+			continue
+		}
+		runtime, node := mappingString(fn.value, "runtime")
+		if node == nil {
+			runtime, node = providerRuntime, providerNode
+		}
+		if node == nil {
+			continue
+		}
+		refs = append(refs, lambdaReference(runtime, serverlessVariable(runtime), path, node.Line, map[string]string{"function": fn.key.Value}))
+	}
+
+	if len(functions) == 0 && providerNode != nil {
+		refs = append(refs, lambdaReference(providerRuntime, serverlessVariable(providerRuntime), path, providerNode.Line, map[string]string{"function": "(provider)"}))
+	}
+	return refs
+}
+
+// serverlessVariable returns the ${...} variable a value depends on, if any
+func serverlessVariable(value string) string {
+	start := strings.Index(value, "${")
+	if start < 0 {
+		return ""
+	}
+	end := strings.Index(value[start:], "}")
+	if end < 0 {
+		return value[start+2:]
+	}
+	return value[start+2 : start+end]
+}
+
+// lambdaReference builds the aws-lambda product reference of a runtime
+func lambdaReference(runtime, unresolved, path string, line int, context map[string]string) Reference {
+	ref := Reference{
+		Product: lambdaProduct,
+		Version: runtime,
+		Subject: "Lambda runtime " + runtime,
+		File:    path,
+		Line:    line,
+		Context: context,
+	}
+	if unresolved != "" {
+		ref.Subject = "Lambda runtime of " + context["function"]
+		ref.Unresolved = []string{unresolved}
+	}
+	return ref
+}
@@ -0,0 +1,170 @@
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestServerlessScan(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		template string
+		want     []Reference
+	}{
+		{
+			name: "SAM with globals and parameters",
+			file: "template.yaml",
+			template: `AWSTemplateFormatVersion: "2010-09-09"
+Transform: AWS::Serverless-2016-10-31
+Parameters:
+  NodeRuntime:
+    Type: String
+    Default: nodejs16.x
+Globals:
+  Function:
+    Runtime: python3.8
+Resources:
+  Api:
+    Type: AWS::Serverless::Function
+  Worker:
+    Type: AWS::Serverless::Function
+    Properties:
+      Runtime: !Ref NodeRuntime
+  Image:
+    Type: AWS::Serverless::Function
+    Properties:
+      PackageType: Image
+  Legacy:
+    Type: AWS::Lambda::Function
+    Properties:
+      Runtime: !Sub "${Lang}3.9"
+  Bucket:
+    Type: AWS::S3::Bucket
+`,
+			want: []Reference{
+				{Product: "aws-lambda", Version: "python3.8", Subject: "Lambda runtime python3.8", Line: 9, Context: map[string]string{"function": "Api", "type": "AWS::Serverless::Function"}},
+				{Product: "aws-lambda", Version: "nodejs16.x", Subject: "Lambda runtime nodejs16.x", Line: 16, Context: map[string]string{"function": "Worker", "type": "AWS::Serverless::Function"}},
+				{Product: "aws-lambda", Subject: "Lambda runtime of Legacy", Line: 24, Context: map[string]string{"function": "Legacy", "type": "AWS::Lambda::Function"}, Unresolved: []string{"!Sub"}},
+			},
+		},
+		{
+			name: "CloudFormation JSON",
+			file: "stack.template",
+			template: `{
+  "Parameters": {"Runtime": {"Type": "String"}},
+  "Resources": {
+    "Fn": {"Type": "AWS::Lambda::Function", "Properties": {"Runtime": "go1.x"}},
+    "Other": {"Type": "AWS::Lambda::Function", "Properties": {"Runtime": {"Ref": "Runtime"}}}
+  }
+}
+`,
+			want: []Reference{
+				{Product: "aws-lambda", Version: "go1.x", Subject: "Lambda runtime go1.x", Line: 4, Context: map[string]string{"function": "Fn", "type": "AWS::Lambda::Function"}},
+				{Product: "aws-lambda", Subject: "Lambda runtime of Other", Line: 5, Context: map[string]string{"function": "Other", "type": "AWS::Lambda::Function"}, Unresolved: []string{"Runtime"}},
+			},
+		},
+		{
+			name: "Serverless Framework",
+			file: "serverless.yml",
+			template: `service: app
+provider:
+  name: aws
+  runtime: nodejs14.x
+functions:
+  hello:
+    handler: handler.hello
+  py:
+    handler: handler.py
+    runtime: ${self:custom.python}
+  container:
+    image: app:latest
+`,
+			want: []Reference{
+				{Product: "aws-lambda", Version: "nodejs14.x", Subject: "Lambda runtime nodejs14.x", Line: 4, Context: map[string]string{"function": "hello"}},
+				{Product: "aws-lambda", Version: "${self:custom.python}", Subject: "Lambda runtime of py", Line: 10, Context: map[string]string{"function": "py"}, Unresolved: []string{"self:custom.python"}},
+			},
+		},
+		{
+			name:     "Serverless Framework provider only",
+			file:     "serverless.yaml",
+			template: "service: app\nprovider:\n  runtime: ruby2.7\n",
+			want: []Reference{
+				{Product: "aws-lambda", Version: "ruby2.7", Subject: "Lambda runtime ruby2.7", Line: 3, Context: map[string]string{"function": "(provider)"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			writeTestFile(t, path, tt.template)
+
+			got, err := NewServerlessScanner().Scan(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range tt.want {
+				tt.want[i].File = path
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerlessMatch(t *testing.T) {
+	s := NewServerlessScanner()
+	for path, want := range map[string]bool{
+		"template.yaml":       true,
+		"template.json":       true,
+		"serverless.yml":      true,
+		"stack.template":      true,
+		"network.cfn.yaml":    true,
+		"api.sam.yml":         true,
+		"template":            false,
+		"templates/deploy.md": false,
+		"values.yaml":         false,
+	} {
+		if got := s.Match(path); got != want {
+			t.Errorf("Match(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestServerlessMatchContent(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"CloudFormation", "AWSTemplateFormatVersion: '2010-09-09'\n", true},
+		{"SAM resources", "Resources:\n  Fn:\n    Type: AWS::Serverless::Function\n", true},
+		{"JSON resources", `{"Resources": {"Fn": {"Type": "AWS::Lambda::Function"}}}`, true},
+		{"Serverless functions", "provider:\n  name: aws\nfunctions:\n  a: {}\n", true},
+		{"Serverless runtime", "provider:\n  runtime: nodejs18.x\n", true},
+		{"e-mail template", "Subject: Welcome {{ .Name }}\nBody: |\n  Hello: world\n", false},
+		{"other resources", "Resources:\n  - cpu\n  - memory\n", false},
+	}
+	s := NewServerlessScanner()
+	for _, tt := range tests {
+		if got := s.MatchContent([]byte(tt.content)); got != tt.want {
+			t.Errorf("MatchContent(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWalkSkipsOtherTemplates(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "email.template"), "Subject: Welcome\nBody: Hello: {{ .Name }}\n")
+	writeTestFile(t, filepath.Join(dir, "stack.template"), "AWSTemplateFormatVersion: '2010-09-09'\nResources: {}\n")
+
+	files, err := NewSet(Options{}).Walk(dir, WalkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(dir, "stack.template")}; !reflect.DeepEqual(files, want) {
+		t.Errorf("Walk() = %q, want %q", files, want)
+	}
+}
@@ -0,0 +1,337 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// containerRunFlags are the boolean flags of docker run and podman run,
+// which unlike the others take no value
+var containerRunFlags = map[string]bool{
+	"-d": true, "--detach": true,
+	"-i": true, "--interactive": true,
+	"-t": true, "--tty": true,
+	"-P": true, "--publish-all": true,
+	"-q": true, "--quiet": true,
+	"--rm": true, "--rmi": true,
+	"--init": true, "--privileged": true, "--read-only": true,
+	"--replace": true, "--no-healthcheck": true, "--no-hosts": true,
+	"--oom-kill-disable": true, "--disable-content-trust": true,
+	"--sig-proxy": true, "--tls-verify": true, "--read-only-tmpfs": true,
+	"--http-proxy": true, "--env-host": true, "--help": true,
+}
+
+// containerGlobalFlags are the boolean global flags of docker and podman,
+// given before the subcommand. The others take a value.
+var containerGlobalFlags = map[string]bool{
+	"-D": true, "--debug": true,
+	"--tls": true, "--tlsverify": true,
+	"-r": true, "--remote": true,
+	"--syslog": true, "--transient-store": true, "--noout": true,
+}
+
+// unitSpecifier matches systemd specifiers such as %i, which depend on the
+// unit instance and can't be resolved from the file
+var unitSpecifier = regexp.MustCompile(`%[a-zA-Z]`)
+
+// SystemdScanner finds container images in Podman Quadlet files and in the
+// docker run or podman run commands of systemd services
+type SystemdScanner struct{}
+
+// NewSystemdScanner creates a systemd unit scanner
+func NewSystemdScanner() *SystemdScanner {
+	return &SystemdScanner{}
+}
+
+// Name identifies the scanner
+func (s *SystemdScanner) Name() string {
+	return "systemd"
+}
+
+// Match recognizes Quadlet .container and .image files and .service units
+func (s *SystemdScanner) Match(path string) bool {
+	switch filepath.Ext(path) {
+	case ".container", ".image", ".service":
+		return true
+	}
+	return false
+}
+
+// Scan returns the Image= of a Quadlet file, or the image of each
+// docker run or podman run in the ExecStart commands of a service
+func (s *SystemdScanner) Scan(path string) ([]Reference, error) {
+	entries, err := readUnitFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch filepath.Ext(path) {
+	case ".container":
+		return quadletImages(entries, "Container", path), nil
+	case ".image":
+		return quadletImages(entries, "Image", path), nil
+	}
+	return serviceImages(entries, path), nil
+}
+
+// quadletImages reads the Image= key of the given section. An image that
+// names another .image unit is followed to it, and one built by a .build
+// unit is skipped.
+func quadletImages(entries []unitEntry, section, path string) []Reference {
+	var refs []Reference
+	context := map[string]string{}
+	for _, e := range entries {
+		if e.section == section && e.key == "ContainerName" {
+			context["containerName"] = e.value
+		}
+	}
+	for _, e := range entries {
+		if e.section != section || e.key != "Image" {
+			continue
+		}
+		switch filepath.Ext(e.value) {
+		case ".build":
+			continue
+		case ".image":
+			imageUnit := filepath.Join(filepath.Dir(path), e.value)
+			imageEntries, err := readUnitFile(imageUnit)
+			if err != nil {
+				continue
+			}
+			imageRefs := quadletImages(imageEntries, "Image", imageUnit)
+			addSources(imageRefs, path)
+			for _, ref := range imageRefs {
+				merged := map[string]string{"unit": filepath.Base(path)}
+				for k, v := range ref.Context {
+					merged[k] = v
+				}
+				for k, v := range context {
+					merged[k] = v
+				}
+				ref.Context = merged
+				refs = append(refs, ref)
+			}
+			continue
+		}
+		refs = append(refs, unitReference(e.value, &expander{lookup: lookupIn(nil), dollarEscape: true}, path, e.line, context))
+	}
+	return refs
+}
+
+// serviceImages finds docker run and podman run in the ExecStart commands
+// of a service. Environment= and EnvironmentFile= variables are expanded.
+func serviceImages(entries []unitEntry, path string) []Reference {
+	env := map[string]string{}
+	var envFiles []string
+	for _, e := range entries {
+		if e.section != "Service" {
+			continue
+		}
+		switch e.key {
+		case "Environment":
+			for _, assignment := range splitCommandLine(e.value) {
+				if key, value, ok := strings.Cut(assignment, "="); ok {
+					env[key] = value
+				}
+			}
+		case "EnvironmentFile":
+			file := strings.TrimPrefix(e.value, "-")
+			if !filepath.IsAbs(file) {
+				file = filepath.Join(filepath.Dir(path), file)
+			}
+			if vars, err := readEnvFile(file); err == nil {
+				for key, value := range vars {
+					env[key] = value
+				}
+				if FileExists(file) {
+					envFiles = append(envFiles, file)
+				}
+			}
+		}
+	}
+
+	var refs []Reference
+	for _, e := range entries {
+		if e.section != "Service" || !strings.HasPrefix(e.key, "ExecStart") {
+			continue
+		}
+		// Strip the prefixes that change how systemd runs the command
+		command := strings.TrimLeft(e.value, "-@:+!")
+		args := splitCommandLine(command)
+		if len(args) < 2 {
+			continue
+		}
+		tool := filepath.Base(args[0])
+		if tool != "docker" && tool != "podman" {
+			continue
+		}
+		imageName := containerRunImage(args[1:])
+		if imageName == "" {
+			continue
+		}
+		context := map[string]string{"directive": e.key, "command": tool + " run"}
+		refs = append(refs, unitReference(imageName, &expander{lookup: lookupIn(env), dollarEscape: true}, path, e.line, context))
+	}
+	addSources(refs, envFiles...)
+	return refs
+}
+
+// containerRunImage returns the image of a run or container run command,
+// given its arguments after docker or podman, global flags included
+func containerRunImage(args []string) string {
+	args = skipGlobalFlags(args)
+	switch {
+	case len(args) > 0 && (args[0] == "run" || args[0] == "create"):
+		args = args[1:]
+	case len(args) > 1 && args[0] == "container" && (args[1] == "run" || args[1] == "create"):
+		args = args[2:]
+	default:
+		return ""
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+			return ""
+		case !strings.HasPrefix(arg, "-") || arg == "-":
+			return arg
+		case strings.Contains(arg, "=") || containerRunFlags[arg]:
+			// --name=web, or a flag without value
+		case !strings.HasPrefix(arg, "--") && len(arg) > 2:
+			// Combined short flags such as -it, or a value attached to a
+			// short flag such as -p8080:80
+		default:
+			i++
+		}
+	}
+	return ""
+}
+
+// skipGlobalFlags drops the global flags of docker or podman, such as
+// --log-level=debug or --context prod, that come before the subcommand
+func skipGlobalFlags(args []string) []string {
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		switch {
+		case strings.Contains(args[0], "=") || containerGlobalFlags[args[0]]:
+			args = args[1:]
+		case len(args) > 1:
+			args = args[2:]
+		default:
+			return nil
+		}
+	}
+	return args
+}
+
+// unitReference builds a reference, treating systemd specifiers as
+// unresolved variables
+func unitReference(raw string, x *expander, path string, line int, context map[string]string) Reference {
+	ref := newReference(raw, x, path, line, context)
+	for _, specifier := range unitSpecifier.FindAllString(strings.ReplaceAll(raw, "%%", ""), -1) {
+		ref.Image = raw
+		ref.Raw = ""
+		ref.Unresolved = appendUnique(ref.Unresolved, specifier)
+	}
+	return ref
+}
+
+// unitEntry is a key of a systemd unit file
+type unitEntry struct {
+	section string
+	key     string
+	value   string
+	line    int
+}
+
+// readUnitFile reads the keys of a systemd unit file, honoring comments and
+// line continuations
+func readUnitFile(path string) ([]unitEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []unitEntry
+	var section, pending string
+	pendingLine := 0
+	lineNo := 0
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		lineNo++
+		line := strings.TrimSpace(sc.Text())
+		if pending == "" {
+			if line == "" || line[0] == '#' || line[0] == ';' {
+				continue
+			}
+			if line[0] == '[' && line[len(line)-1] == ']' {
+				section = line[1 : len(line)-1]
+				continue
+			}
+			pendingLine = lineNo
+		} else if line != "" && (line[0] == '#' || line[0] == ';') {
+			// Comments may be interleaved with continuation lines
+			continue
+		}
+
+		if strings.HasSuffix(line, "\\") {
+			pending += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		pending += line
+
+		if key, value, ok := strings.Cut(pending, "="); ok {
+			entries = append(entries, unitEntry{
+				section: section,
+				key:     strings.TrimSpace(key),
+				value:   strings.TrimSpace(value),
+				line:    pendingLine,
+			})
+		}
+		pending = ""
+	}
+	return entries, sc.Err()
+}
+
+// splitCommandLine splits a systemd command line into words, honoring
+// single and double quotes and backslash escapes
+func splitCommandLine(s string) []string {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+			inWord = true
+		case c == '\\' && i+1 < len(s) && quote != '\'':
+			i++
+			word.WriteByte(s[i])
+			inWord = true
+		case quote == 0 && (c == ' ' || c == '\t'):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
@@ -0,0 +1,146 @@
+package scanner
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestContainerRunImage(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"run", []string{"run", "nginx:1.25"}, "nginx:1.25"},
+		{"flags with values", []string{"run", "--name", "web", "-p", "80:80", "-v=/data:/data", "nginx:1.25", "nginx", "-g", "daemon off;"}, "nginx:1.25"},
+		{"boolean and combined flags", []string{"run", "--rm", "-it", "-p8080:80", "redis:7"}, "redis:7"},
+		{"create", []string{"create", "-d", "postgres:12"}, "postgres:12"},
+		{"container run", []string{"container", "run", "--rm", "alpine:3.19", "true"}, "alpine:3.19"},
+		{"double dash", []string{"run", "--rm", "--", "busybox:1.36"}, "busybox:1.36"},
+		{"global flag with equals", []string{"--log-level=debug", "run", "--rm", "node:20"}, "node:20"},
+		{"global flag with value", []string{"--context", "prod", "run", "python:3.12"}, "python:3.12"},
+		{"global boolean flags", []string{"--remote", "-D", "container", "run", "ruby:3.3"}, "ruby:3.3"},
+		{"other subcommand", []string{"pull", "nginx:1.25"}, ""},
+		{"global flag then other subcommand", []string{"--log-level", "debug", "stop", "web"}, ""},
+		{"only flags", []string{"--log-level"}, ""},
+		{"no image", []string{"run", "--rm"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containerRunImage(tt.args); got != tt.want {
+				t.Errorf("containerRunImage(%q) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := map[string][]string{
+		`/usr/bin/podman run nginx`:           {"/usr/bin/podman", "run", "nginx"},
+		`docker run -e "A=b c" 'x y' z\ w`:    {"docker", "run", "-e", "A=b c", "x y", "z w"},
+		"podman\trun  --rm\timage":            {"podman", "run", "--rm", "image"},
+		`echo 'single \n quoted' "esc\"aped"`: {"echo", `single \n quoted`, `esc"aped`},
+	}
+	for line, want := range tests {
+		if got := splitCommandLine(line); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitCommandLine(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestSystemdScan(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		unit  string
+		files map[string]string
+		want  []Reference
+	}{
+		{
+			name: "quadlet container",
+			file: "web.container",
+			unit: `[Unit]
+Description=Web
+
+[Container]
+# Pinned image
+ContainerName=web
+Image=docker.io/library/nginx:1.20
+`,
+			want: []Reference{
+				{Image: "docker.io/library/nginx:1.20", Line: 7, Context: map[string]string{"containerName": "web"}},
+			},
+		},
+		{
+			name: "quadlet container with image unit",
+			file: "api.container",
+			unit: `[Container]
+ContainerName=api
+Image=api.image
+`,
+			files: map[string]string{"api.image": "[Image]\nImage=docker.io/library/node:16\n"},
+			want: []Reference{
+				{Image: "docker.io/library/node:16", Line: 2, Context: map[string]string{"containerName": "api", "unit": "api.container"}, Sources: []string{"api.container"}},
+			},
+		},
+		{
+			name: "quadlet built image and specifier",
+			file: "worker@.container",
+			unit: `[Container]
+Image=worker.build
+Image=example/worker:%i
+`,
+			want: []Reference{
+				{Image: "example/worker:%i", Line: 3, Context: map[string]string{}, Unresolved: []string{"%i"}},
+			},
+		},
+		{
+			name: "service",
+			file: "app.service",
+			unit: `[Service]
+Environment=TAG=7.2 "OTHER=a b"
+EnvironmentFile=-app.env
+ExecStartPre=-/usr/bin/docker pull redis:${TAG}
+ExecStart=/usr/bin/docker --log-level=warn run --rm \
+    --name app \
+    redis:${TAG}
+ExecStartPost=/usr/bin/podman --connection prod run postgres:$PG
+ExecStop=/usr/bin/docker stop app
+`,
+			files: map[string]string{"app.env": "PG=12\n"},
+			want: []Reference{
+				{Image: "redis:7.2", Raw: "redis:${TAG}", Line: 5, Context: map[string]string{"directive": "ExecStart", "command": "docker run"}, Sources: []string{"app.env"}},
+				{Image: "postgres:12", Raw: "postgres:$PG", Line: 8, Context: map[string]string{"directive": "ExecStartPost", "command": "podman run"}, Sources: []string{"app.env"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeTestFile(t, filepath.Join(dir, name), content)
+			}
+			path := filepath.Join(dir, tt.file)
+			writeTestFile(t, path, tt.unit)
+
+			got, err := NewSystemdScanner().Scan(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range tt.want {
+				if tt.want[i].Context["unit"] != "" {
+					tt.want[i].File = filepath.Join(dir, "api.image")
+				} else {
+					tt.want[i].File = path
+				}
+				for j, source := range tt.want[i].Sources {
+					tt.want[i].Sources[j] = filepath.Join(dir, source)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,529 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// terraformResources maps the Terraform resource types the scanner reads to
+// the function that extracts their references
+var terraformResources = map[string]func(m *terraformModule, r terraformResource) []Reference{
+	"aws_db_instance":                    awsDatabaseEngine("engine", "engine_version", ""),
+	"aws_rds_cluster":                    awsDatabaseEngine("engine", "engine_version", "aurora"),
+	"aws_elasticache_cluster":            awsDatabaseEngine("engine", "engine_version", ""),
+	"aws_elasticache_replication_group":  awsDatabaseEngine("engine", "engine_version", "redis"),
+	"aws_elasticache_serverless_cache":   awsDatabaseEngine("engine", "major_engine_version", ""),
+	"aws_mq_broker":                      awsDatabaseEngine("engine_type", "engine_version", ""),
+	"aws_msk_cluster":                    fixedDatabaseEngine("kafka", "kafka_version"),
+	"aws_elasticsearch_domain":           fixedDatabaseEngine("elasticsearch", "elasticsearch_version"),
+	"aws_opensearch_domain":              openSearchEngine,
+	"google_sql_database_instance":       cloudSQLEngine,
+	"azurerm_postgresql_flexible_server": fixedDatabaseEngine("postgres", "version"),
+	"azurerm_postgresql_server":          fixedDatabaseEngine("postgres", "version"),
+	"azurerm_mysql_flexible_server":      fixedDatabaseEngine("mysql", "version"),
+	"azurerm_redis_cache":                fixedDatabaseEngine("redis", "redis_version"),
+	"azurerm_mariadb_server":             fixedDatabaseEngine("mariadb", "version"),
+	"google_redis_instance":              redisVersionEngine,
+	"aws_memorydb_cluster":               awsDatabaseEngine("engine", "engine_version", "redis"),
+	"aws_eks_cluster":                    clusterVersion("amazon-eks", "version"),
+	"aws_eks_node_group":                 clusterVersion("amazon-eks", "version"),
+	"google_container_cluster":           clusterVersion("google-kubernetes-engine", "min_master_version"),
+	"google_container_node_pool":         clusterVersion("google-kubernetes-engine", "version"),
+	"azurerm_kubernetes_cluster":         clusterVersion("azure-kubernetes-service", "kubernetes_version"),
+	"aws_ecs_task_definition":            ecsContainerDefinitions,
+}
+
+// databaseEngines maps database engine names, lower-cased, to endoflife.date
+// products. Engines missing from the map, such as oracle-ee, are skipped.
+var databaseEngines = map[string]string{
+	"postgres":          "postgresql",
+	"postgresql":        "postgresql",
+	"aurora-postgresql": "postgresql",
+	"mysql":             "mysql",
+	"aurora":            "mysql",
+	"aurora-mysql":      "mysql",
+	"mariadb":           "mariadb",
+	"redis":             "redis",
+	"valkey":            "valkey",
+	"activemq":          "apache-activemq",
+	"rabbitmq":          "rabbitmq",
+	"kafka":             "apache-kafka",
+	"elasticsearch":     "elasticsearch",
+	"opensearch":        "opensearch",
+}
+
+// TerraformScanner finds managed database engine and Kubernetes cluster
+// versions, and ECS container images, in Terraform configurations
+type TerraformScanner struct{}
+
+// NewTerraformScanner creates a Terraform scanner
+func NewTerraformScanner() *TerraformScanner {
+	return &TerraformScanner{}
+}
+
+// Name identifies the scanner
+func (s *TerraformScanner) Name() string {
+	return "terraform"
+}
+
+// Match recognizes *.tf files
+func (s *TerraformScanner) Match(path string) bool {
+	return filepath.Ext(path) == ".tf"
+}
+
+// Scan returns the engine or cluster version, or the images, of every
+// supported resource in the file.
+// Variables and locals are resolved from the other .tf files of the module.
+func (s *TerraformScanner) Scan(path string) ([]Reference, error) {
+	file, err := parseHCL(path)
+	if err != nil {
+		return nil, err
+	}
+	m := loadTerraformModule(path, file)
+
+	var refs []Reference
+	for _, r := range terraformResourceBlocks(file, path) {
+		if extract := terraformResources[r.typ]; extract != nil {
+			refs = append(refs, extract(m, r)...)
+		}
+	}
+	addSources(refs, m.files...)
+	return refs, nil
+}
+
+// parseHCL reads and parses a native syntax HCL file
+func parseHCL(path string) (*hcl.File, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	file, diags := hclsyntax.ParseConfig(src, filepath.Base(path), hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	return file, nil
+}
+
+// terraformResource is a resource block of a Terraform file
+type terraformResource struct {
+	typ     string
+	address string
+	body    *hclsyntax.Body
+	src     []byte
+	path    string
+	line    int
+}
+
+// terraformResourceBlocks returns the resource blocks of a file
+func terraformResourceBlocks(file *hcl.File, path string) []terraformResource {
+	var resources []terraformResource
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		if block.Type != "resource" || len(block.Labels) != 2 {
+			continue
+		}
+		resources = append(resources, terraformResource{
+			typ:     block.Labels[0],
+			address: block.Labels[0] + "." + block.Labels[1],
+			body:    block.Body,
+			src:     file.Bytes,
+			path:    path,
+			line:    block.TypeRange.Start.Line,
+		})
+	}
+	return resources
+}
+
+// terraformModule holds the variable defaults and locals of the module a
+// file belongs to. Nomad job files, which share the syntax, are a module of
+// their own.
+type terraformModule struct {
+	dir       string
+	variables map[string]cty.Value
+	locals    map[string]cty.Value
+	// files are the other files the module was read from
+	files []string
+}
+
+// terraformFunctions are the Terraform functions available when evaluating
+// expressions. They cover the string manipulation commonly used to build
+// versions.
+var terraformFunctions = map[string]function.Function{
+	"coalesce":   stdlib.CoalesceFunc,
+	"element":    stdlib.ElementFunc,
+	"format":     stdlib.FormatFunc,
+	"join":       stdlib.JoinFunc,
+	"jsonencode": stdlib.JSONEncodeFunc,
+	"lookup":     stdlib.LookupFunc,
+	"lower":      stdlib.LowerFunc,
+	"merge":      stdlib.MergeFunc,
+	"replace":    stdlib.ReplaceFunc,
+	"split":      stdlib.SplitFunc,
+	"substr":     stdlib.SubstrFunc,
+	"tostring":   stdlib.MakeToFunc(cty.String),
+	"trimprefix": stdlib.TrimPrefixFunc,
+	"trimspace":  stdlib.TrimSpaceFunc,
+	"trimsuffix": stdlib.TrimSuffixFunc,
+	"upper":      stdlib.UpperFunc,
+}
+
+// loadTerraformModule collects the variable defaults and locals declared in
+// the .tf files next to path, and the values set by terraform.tfvars and
+// *.auto.tfvars. file is the already parsed file at path.
+func loadTerraformModule(path string, file *hcl.File) *terraformModule {
+	dir := filepath.Dir(path)
+	bodies := []*hclsyntax.Body{file.Body.(*hclsyntax.Body)}
+	var files []string
+	siblings, _ := filepath.Glob(filepath.Join(dir, "*.tf"))
+	for _, sibling := range siblings {
+		if filepath.Clean(sibling) == filepath.Clean(path) {
+			continue
+		}
+		if other, err := parseHCL(sibling); err == nil {
+			bodies = append(bodies, other.Body.(*hclsyntax.Body))
+			files = append(files, sibling)
+		}
+	}
+
+	// Values files override the defaults, later files winning
+	values := map[string]cty.Value{}
+	tfvars := []string{filepath.Join(dir, "terraform.tfvars")}
+	auto, _ := filepath.Glob(filepath.Join(dir, "*.auto.tfvars"))
+	sort.Strings(auto)
+	for _, vars := range append(tfvars, auto...) {
+		varsFile, err := parseHCL(vars)
+		if err != nil {
+			continue
+		}
+		files = append(files, vars)
+		for name, attr := range varsFile.Body.(*hclsyntax.Body).Attributes {
+			if value, diags := attr.Expr.Value(nil); !diags.HasErrors() {
+				values[name] = value
+			}
+		}
+	}
+
+	m := newHCLModule(bodies, values)
+	m.dir = dir
+	m.files = files
+	return m
+}
+
+// newHCLModule evaluates the variable and locals blocks of bodies. values
+// override the variable defaults.
+func newHCLModule(bodies []*hclsyntax.Body, values map[string]cty.Value) *terraformModule {
+	m := &terraformModule{
+		variables: map[string]cty.Value{},
+		locals:    map[string]cty.Value{},
+	}
+
+	localExprs := map[string]hclsyntax.Expression{}
+	for _, body := range bodies {
+		for _, block := range body.Blocks {
+			switch {
+			case block.Type == "variable" && len(block.Labels) == 1:
+				if attr := block.Body.Attributes["default"]; attr != nil {
+					if value, diags := attr.Expr.Value(nil); !diags.HasErrors() && !value.IsNull() {
+						m.variables[block.Labels[0]] = value
+					}
+				}
+			case block.Type == "locals":
+				for name, attr := range block.Body.Attributes {
+					localExprs[name] = attr.Expr
+				}
+			}
+		}
+	}
+	for name, value := range values {
+		m.variables[name] = value
+	}
+
+	// Locals may refer to each other, so evaluate those whose references
+	// are known until no more can be
+	for progress := true; progress; {
+		progress = false
+		for name, expr := range localExprs {
+			if len(m.missing(expr)) > 0 {
+				continue
+			}
+			if value, diags := expr.Value(m.evalContext()); !diags.HasErrors() {
+				m.locals[name] = value
+			}
+			delete(localExprs, name)
+			progress = true
+		}
+	}
+	return m
+}
+
+// evalContext returns the context expressions are evaluated in. Terraform
+// modules also get path.module and the file function.
+func (m *terraformModule) evalContext() *hcl.EvalContext {
+	ctx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{
+			"var":   cty.ObjectVal(m.variables),
+			"local": cty.ObjectVal(m.locals),
+		},
+		Functions: terraformFunctions,
+	}
+	if m.dir != "" {
+		// Paths are relative to the module, which file resolves
+		ctx.Variables["path"] = cty.ObjectVal(map[string]cty.Value{
+			"module": cty.StringVal("."),
+			"root":   cty.StringVal("."),
+		})
+		ctx.Functions = map[string]function.Function{"file": m.fileFunc()}
+		for name, fn := range terraformFunctions {
+			ctx.Functions[name] = fn
+		}
+	}
+	return ctx
+}
+
+// fileFunc returns the Terraform file function, reading paths relative to
+// the module directory
+func (m *terraformModule) fileFunc() function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "path", Type: cty.String}},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, _ cty.Type) (cty.Value, error) {
+			path := args[0].AsString()
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(m.dir, path)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			m.files = appendUnique(m.files, path)
+			return cty.StringVal(string(data)), nil
+		},
+	})
+}
+
+// missing returns the references of expr to values the module doesn't know,
+// such as variables without default, data sources or other resources
+func (m *terraformModule) missing(expr hclsyntax.Expression) []string {
+	var names []string
+	for _, traversal := range expr.Variables() {
+		root := traversal.RootName()
+		name := traversalAttr(traversal, 1)
+		switch root {
+		case "var":
+			if _, ok := m.variables[name]; ok {
+				continue
+			}
+		case "local":
+			if _, ok := m.locals[name]; ok {
+				continue
+			}
+		case "path":
+			if m.dir != "" {
+				continue
+			}
+		}
+		if name != "" {
+			root += "." + name
+		}
+		if data := traversalAttr(traversal, 2); root == "data."+name && data != "" {
+			root += "." + data
+		}
+		names = appendUnique(names, root)
+	}
+	return names
+}
+
+// traversalAttr returns the attribute name at step i of a traversal, or ""
+func traversalAttr(traversal hcl.Traversal, i int) string {
+	if len(traversal) > i {
+		if attr, ok := traversal[i].(hcl.TraverseAttr); ok {
+			return attr.Name
+		}
+	}
+	return ""
+}
+
+// stringAttribute evaluates the attribute name of body as a string. It
+// returns the attribute's line, or 0 when the attribute is not set, and the
+// references that prevented the evaluation.
+func (m *terraformModule) stringAttribute(body *hclsyntax.Body, name string) (string, int, []string) {
+	attr := body.Attributes[name]
+	if attr == nil {
+		return "", 0, nil
+	}
+	value, unresolved := m.stringValue(attr.Expr, name)
+	return value, attr.SrcRange.Start.Line, unresolved
+}
+
+// stringValue evaluates expr as a string. When it can't be evaluated, it
+// returns the references it depends on, or name when those are all known.
+func (m *terraformModule) stringValue(expr hclsyntax.Expression, name string) (string, []string) {
+	if missing := m.missing(expr); len(missing) > 0 {
+		return "", missing
+	}
+	value, diags := expr.Value(m.evalContext())
+	if diags.HasErrors() || value.IsNull() || !value.IsWhollyKnown() {
+		return "", []string{name}
+	}
+	value, err := convert.Convert(value, cty.String)
+	if err != nil {
+		return "", []string{name}
+	}
+	return value.AsString(), nil
+}
+
+// imageReference builds the reference of an image expression. src is the
+// file the expression was parsed from, used to report it as written.
+func (m *terraformModule) imageReference(expr hclsyntax.Expression, src []byte, path string, context map[string]string) Reference {
+	raw := strings.Trim(string(expr.Range().SliceBytes(src)), `"`)
+	value, unresolved := m.stringValue(expr, "image")
+	ref := Reference{
+		Image:   value,
+		File:    path,
+		Line:    expr.Range().Start.Line,
+		Context: context,
+	}
+	if len(unresolved) > 0 {
+		ref.Image = raw
+		ref.Unresolved = unresolved
+	} else if value != raw {
+		ref.Raw = raw
+	}
+	return ref
+}
+
+// appendUnique appends s to list unless it is already there
+func appendUnique(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// awsDatabaseEngine reads the engine and version attributes of a resource.
+// defaultEngine applies when the engine attribute is not set.
+func awsDatabaseEngine(engineAttr, versionAttr, defaultEngine string) func(m *terraformModule, r terraformResource) []Reference {
+	return func(m *terraformModule, r terraformResource) []Reference {
+		engine, engineLine, unresolved := m.stringAttribute(r.body, engineAttr)
+		if engineLine == 0 {
+			engine = defaultEngine
+		}
+		version, versionLine, versionUnresolved := m.stringAttribute(r.body, versionAttr)
+		return databaseReference(r, engine, version, versionLine, append(unresolved, versionUnresolved...))
+	}
+}
+
+// fixedDatabaseEngine reads the version attribute of a resource whose
+// engine is implied by its type
+func fixedDatabaseEngine(engine, versionAttr string) func(m *terraformModule, r terraformResource) []Reference {
+	return func(m *terraformModule, r terraformResource) []Reference {
+		version, line, unresolved := m.stringAttribute(r.body, versionAttr)
+		return databaseReference(r, engine, version, line, unresolved)
+	}
+}
+
+// openSearchEngine reads engine_version of an aws_opensearch_domain, written
+// as OpenSearch_2.11 or Elasticsearch_7.10
+func openSearchEngine(m *terraformModule, r terraformResource) []Reference {
+	value, line, unresolved := m.stringAttribute(r.body, "engine_version")
+	engine, version, ok := strings.Cut(value, "_")
+	if !ok {
+		engine, version = "opensearch", value
+	}
+	return databaseReference(r, engine, version, line, unresolved)
+}
+
+// cloudSQLEngine reads database_version of a google_sql_database_instance,
+// written as POSTGRES_14 or MYSQL_8_0
+func cloudSQLEngine(m *terraformModule, r terraformResource) []Reference {
+	value, line, unresolved := m.stringAttribute(r.body, "database_version")
+	engine, version, _ := strings.Cut(value, "_")
+	return databaseReference(r, engine, strings.ReplaceAll(version, "_", "."), line, unresolved)
+}
+
+// redisVersionEngine reads redis_version of a google_redis_instance, written
+// as REDIS_7_0
+func redisVersionEngine(m *terraformModule, r terraformResource) []Reference {
+	value, line, unresolved := m.stringAttribute(r.body, "redis_version")
+	version := strings.ReplaceAll(strings.TrimPrefix(strings.ToUpper(value), "REDIS_"), "_", ".")
+	return databaseReference(r, "redis", version, line, unresolved)
+}
+
+// databaseReference builds the product reference of a database engine. It
+// returns nothing when the engine is not tracked or no version is pinned, in
+// which case the provider picks its default.
+func databaseReference(r terraformResource, engine, version string, line int, unresolved []string) []Reference {
+	product, ok := databaseEngines[strings.ToLower(engine)]
+	if engine != "" && !ok {
+		return nil
+	}
+	context := map[string]string{"resource": r.address}
+	if line == 0 {
+		line = r.line
+	}
+	if len(unresolved) > 0 {
+		return []Reference{{
+			Product:    product,
+			Subject:    "engine version of " + r.address,
+			File:       r.path,
+			Line:       line,
+			Context:    context,
+			Unresolved: unresolved,
+		}}
+	}
+
+	// Only the leading version counts, e.g. 8.0 in 8.0.mysql_aurora.3.02.0
+	match := leadingVersion.FindStringSubmatch(version)
+	if !ok || match == nil {
+		return nil
+	}
+	return []Reference{{
+		Product: product,
+		Version: match[1],
+		Subject: engine + " " + version + " engine of " + r.address,
+		File:    r.path,
+		Line:    line,
+		Context: context,
+	}}
+}
+
+// clusterVersion reads the Kubernetes version attribute of a managed cluster
+// or node pool. Resources without one follow the provider's default.
+func clusterVersion(product, versionAttr string) func(m *terraformModule, r terraformResource) []Reference {
+	return func(m *terraformModule, r terraformResource) []Reference {
+		version, line, unresolved := m.stringAttribute(r.body, versionAttr)
+		if line == 0 {
+			return nil
+		}
+		ref := Reference{
+			Product: product,
+			File:    r.path,
+			Line:    line,
+			Context: map[string]string{"resource": r.address},
+		}
+		if len(unresolved) > 0 {
+			ref.Subject = "Kubernetes version of " + r.address
+			ref.Unresolved = unresolved
+			return []Reference{ref}
+		}
+
+		// GKE versions carry a suffix, as in 1.27.3-gke.1700
+		match := leadingVersion.FindStringSubmatch(version)
+		if match == nil {
+			return nil
+		}
+		ref.Version = match[1]
+		ref.Subject = "Kubernetes " + version + " of " + r.address
+		return []Reference{ref}
+	}
+}
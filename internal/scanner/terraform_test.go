@@ -0,0 +1,154 @@
+package scanner
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTerraformScan(t *testing.T) {
+	tests := []struct {
+		name  string
+		main  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "database engines",
+			main: `resource "aws_db_instance" "db" {
+  engine         = "postgres"
+  engine_version = "11.22"
+}
+
+resource "aws_rds_cluster" "aurora" {
+  engine_version = "5.7.mysql_aurora.2.11.2"
+}
+
+resource "aws_db_instance" "oracle" {
+  engine         = "oracle-ee"
+  engine_version = "19"
+}
+
+resource "aws_db_instance" "default" {
+  engine = "mysql"
+}
+
+resource "google_sql_database_instance" "sql" {
+  database_version = "MYSQL_5_7"
+}
+
+resource "google_redis_instance" "cache" {
+  redis_version = "REDIS_6_X"
+}
+
+resource "aws_opensearch_domain" "search" {
+  engine_version = "Elasticsearch_7.10"
+}
+
+resource "azurerm_redis_cache" "azure" {
+  redis_version = 6
+}
+`,
+			want: []string{
+				"aws_db_instance.db: postgresql 11.22 line 3",
+				"aws_rds_cluster.aurora: mysql 5.7 line 7",
+				"google_sql_database_instance.sql: mysql 5.7 line 20",
+				"google_redis_instance.cache: redis 6 line 24",
+				"aws_opensearch_domain.search: elasticsearch 7.10 line 28",
+				"azurerm_redis_cache.azure: redis 6 line 32",
+			},
+		},
+		{
+			name: "clusters",
+			main: `resource "aws_eks_cluster" "eks" {
+  version = "1.23"
+}
+
+resource "google_container_cluster" "gke" {
+  min_master_version = "1.27.3-gke.1700"
+}
+
+resource "azurerm_kubernetes_cluster" "aks" {
+  name = "aks"
+}
+`,
+			want: []string{
+				"aws_eks_cluster.eks: amazon-eks 1.23 line 2",
+				"google_container_cluster.gke: google-kubernetes-engine 1.27.3 line 6",
+			},
+		},
+		{
+			name: "variables, locals and values files",
+			main: `locals {
+  major   = "${var.pg_major}"
+  version = format("%s.%s", local.major, var.pg_minor)
+}
+
+resource "aws_db_instance" "db" {
+  engine         = "postgres"
+  engine_version = local.version
+}
+
+resource "aws_elasticache_replication_group" "redis" {
+  engine_version = var.redis_version
+}
+
+resource "aws_msk_cluster" "kafka" {
+  kafka_version = data.aws_msk_kafka_version.latest.version
+}
+`,
+			files: map[string]string{
+				"variables.tf":     "variable \"pg_major\" {\n  default = \"12\"\n}\nvariable \"pg_minor\" {\n  default = \"1\"\n}\nvariable \"redis_version\" {}\n",
+				"terraform.tfvars": "pg_major = \"13\"\n",
+				"prod.auto.tfvars": "pg_minor = \"4\"\n",
+				"staging.tfvars":   "pg_major = \"9\"\n",
+			},
+			want: []string{
+				"aws_db_instance.db: postgresql 13.4 line 8",
+				"aws_elasticache_replication_group.redis: redis unresolved [var.redis_version] line 12",
+				"aws_msk_cluster.kafka: apache-kafka unresolved [data.aws_msk_kafka_version.latest] line 16",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeTestFile(t, filepath.Join(dir, name), content)
+			}
+			path := filepath.Join(dir, "main.tf")
+			writeTestFile(t, path, tt.main)
+
+			refs, err := NewTerraformScanner().Scan(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := productReferences(refs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerraformScanInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.tf")
+	writeTestFile(t, path, "resource \"aws_db_instance\" {\n")
+	if _, err := NewTerraformScanner().Scan(path); err == nil {
+		t.Error("Scan() of invalid HCL succeeded, want an error")
+	}
+}
+
+// productReferences describes product references as
+// "<resource>: <product> <version> line <n>"
+func productReferences(refs []Reference) []string {
+	var described []string
+	for _, ref := range refs {
+		version := ref.Version
+		if len(ref.Unresolved) > 0 {
+			version = fmt.Sprintf("unresolved %v", ref.Unresolved)
+		}
+		described = append(described, fmt.Sprintf("%s: %s %s line %d", ref.Context["resource"], ref.Product, version, ref.Line))
+	}
+	return described
+}
@@ -0,0 +1,244 @@
+package scanner
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ContentMatcher is implemented by scanners whose Match accepts files by
+// extension alone, such as any YAML file. When walking a directory, their
+// files are only scanned when MatchContent accepts the file's content.
+type ContentMatcher interface {
+	MatchContent(data []byte) bool
+}
+
+// WalkOptions selects the files of a directory tree to scan
+type WalkOptions struct {
+	// Include limits the walk to files matching one of these globs
+	Include []string
+	// Exclude skips the files and directories matching one of these globs
+	Exclude []string
+}
+
+// Walk returns the files under root that a scanner handles, in lexical
+// order. The .git directory, files ignored by .gitignore, the templates of
+// Helm charts and the files included by a kustomization are skipped. Globs
+// without a slash match the name of a file or directory at any depth, others
+// its path relative to root; ** matches any number of directories.
+func (s *Set) Walk(root string, opts WalkOptions) ([]string, error) {
+	include := parsePatterns(opts.Include, "")
+	exclude := parsePatterns(opts.Exclude, "")
+	ignored := readIgnoreFile(filepath.Join(root, ".git", "info", "exclude"), "")
+
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if rel == "." {
+				ignored = append(ignored, readIgnoreFile(filepath.Join(p, ".gitignore"), "")...)
+				return nil
+			}
+			if d.Name() == ".git" || matchAny(exclude, rel, true) || isIgnored(ignored, rel, true) {
+				return filepath.SkipDir
+			}
+			if d.Name() == "templates" && FileExists(filepath.Join(filepath.Dir(p), "Chart.yaml")) {
+				// Chart templates are Go templates, not YAML
+				return filepath.SkipDir
+			}
+			ignored = append(ignored, readIgnoreFile(filepath.Join(p, ".gitignore"), rel)...)
+			return nil
+		}
+
+		if !d.Type().IsRegular() || matchAny(exclude, rel, false) || isIgnored(ignored, rel, false) {
+			return nil
+		}
+		if len(include) > 0 && !matchAny(include, rel, false) {
+			return nil
+		}
+		sc := s.Detect(p)
+		if sc == nil {
+			return nil
+		}
+		if cm, ok := sc.(ContentMatcher); ok {
+			if data, err := os.ReadFile(p); err == nil && !cm.MatchContent(data) {
+				return nil
+			}
+		}
+		files = append(files, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return skipKustomized(files), nil
+}
+
+// skipKustomized leaves out the files that a kustomization among files
+// includes, such as its manifests and bases: their images are reported
+// through the kustomization, as it renders them
+func skipKustomized(files []string) []string {
+	kustomize := NewKustomizeScanner()
+	included := map[string]bool{}
+	for _, file := range files {
+		if kustomize.Match(file) {
+			for _, include := range kustomizationIncludes(file) {
+				included[filepath.Clean(include)] = true
+			}
+		}
+	}
+
+	kept := files[:0]
+	for _, file := range files {
+		if !included[filepath.Clean(file)] {
+			kept = append(kept, file)
+		}
+	}
+	return kept
+}
+
+// globPattern is an include or exclude glob, or a .gitignore pattern
+type globPattern struct {
+	pattern string
+	// base is the directory of the .gitignore file, relative to the root
+	base     string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// parsePattern parses a glob in .gitignore syntax
+func parsePattern(line, base string) (globPattern, bool) {
+	p := globPattern{base: base}
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+	if strings.Contains(line, "/") {
+		p.anchored = true
+		line = strings.TrimPrefix(line, "/")
+	}
+	p.pattern = line
+	return p, line != ""
+}
+
+// parsePatterns parses a list of globs
+func parsePatterns(lines []string, base string) []globPattern {
+	var patterns []globPattern
+	for _, line := range lines {
+		if p, ok := parsePattern(line, base); ok {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// match reports whether the slash-separated path rel, relative to the walk
+// root, matches the pattern
+func (p globPattern) match(rel string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	if p.base != "" {
+		if !strings.HasPrefix(rel, p.base+"/") {
+			return false
+		}
+		rel = rel[len(p.base)+1:]
+	}
+	if p.anchored {
+		return matchGlob(p.pattern, rel)
+	}
+	return matchGlob(p.pattern, path.Base(rel))
+}
+
+// matchAny reports whether rel matches one of the patterns
+func matchAny(patterns []globPattern, rel string, isDir bool) bool {
+	for _, p := range patterns {
+		if p.match(rel, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// isIgnored applies .gitignore patterns in order, the last match winning
+func isIgnored(patterns []globPattern, rel string, isDir bool) bool {
+	ignored := false
+	for _, p := range patterns {
+		if p.match(rel, isDir) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// matchGlob matches a slash-separated path against a glob where ** matches
+// any number of path segments and other segments follow path.Match
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// readIgnoreFile reads the patterns of a .gitignore file, or none when it
+// doesn't exist. base is its directory relative to the walk root.
+func readIgnoreFile(file, base string) []globPattern {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var patterns []globPattern
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), " ")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if p, ok := parsePattern(line, base); ok {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// FileExists reports whether a regular file exists at path
+func FileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
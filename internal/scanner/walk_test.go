@@ -0,0 +1,151 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"*.tf", "main.tf", true},
+		{"*.tf", "main.tfvars", false},
+		{"vendor", "vendor", true},
+		{"deploy/*.yaml", "deploy/web.yaml", true},
+		{"deploy/*.yaml", "deploy/prod/web.yaml", false},
+		{"**/*.yaml", "web.yaml", true},
+		{"**/*.yaml", "a/b/c/web.yaml", true},
+		{"deploy/**", "deploy/prod/web.yaml", true},
+		{"deploy/**", "deploy", true},
+		{"deploy/**/web.yaml", "deploy/web.yaml", true},
+		{"deploy/**/web.yaml", "deploy/a/b/web.yaml", true},
+		{"deploy/**/web.yaml", "other/a/web.yaml", false},
+		{"a/?.txt", "a/b.txt", true},
+		{"a/[bc].txt", "a/d.txt", false},
+	}
+	for _, tt := range tests {
+		if got := matchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsIgnored(t *testing.T) {
+	patterns := parsePatterns([]string{
+		"*.log",
+		"build/",
+		"/dist",
+		"!keep.log",
+		"docs/*.md",
+	}, "")
+	nested := parsePatterns([]string{"generated", "!important"}, "svc/api")
+	patterns = append(patterns, nested...)
+
+	tests := []struct {
+		rel   string
+		isDir bool
+		want  bool
+	}{
+		{"debug.log", false, true},
+		{"logs/debug.log", false, true},
+		{"keep.log", false, false},
+		{"build", true, true},
+		{"build", false, false},
+		{"svc/build", true, true},
+		{"dist", true, true},
+		{"svc/dist", true, false},
+		{"docs/README.md", false, true},
+		{"docs/api/README.md", false, false},
+		{"svc/api/generated", true, true},
+		{"svc/api/important", false, false},
+		{"svc/web/generated", true, false},
+		{"Dockerfile", false, false},
+	}
+	for _, tt := range tests {
+		if got := isIgnored(patterns, tt.rel, tt.isDir); got != tt.want {
+			t.Errorf("isIgnored(%q, dir=%v) = %v, want %v", tt.rel, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":                         "ignored/\n*.tmp.yaml\n",
+		"Dockerfile":                         "FROM node:18\n",
+		"ignored/Dockerfile":                 "FROM node:16\n",
+		"app/compose.yaml":                   "services: {web: {image: nginx}}\n",
+		"app/values.tmp.yaml":                "image: {repository: nginx}\n",
+		"app/config.yaml":                    "log_level: debug\n",
+		"app/deploy.yaml":                    "apiVersion: v1\nkind: Pod\nspec: {containers: [{image: nginx}]}\n",
+		"vendor/Dockerfile":                  "FROM golang:1.20\n",
+		"chart/Chart.yaml":                   "name: web\n",
+		"chart/values.yaml":                  "image: {repository: nginx, tag: '1.25'}\n",
+		"chart/templates/deploy.yaml":        "apiVersion: v1\nkind: Pod\n",
+		"k/base/kustomization.yaml":          "resources: [deploy.yaml]\n",
+		"k/base/deploy.yaml":                 "apiVersion: v1\nkind: Pod\nspec: {containers: [{image: nginx:1.20}]}\n",
+		"k/overlays/prod/kustomization.yaml": "resources: [../../base]\nimages: [{name: nginx, newTag: '1.25'}]\npatches: [{path: patch.yaml}]\n",
+		"k/overlays/prod/patch.yaml":         "apiVersion: v1\nkind: Pod\nspec: {containers: [{image: nginx:1.18}]}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		opts WalkOptions
+		want []string
+	}{
+		{
+			name: "default",
+			want: []string{
+				"Dockerfile",
+				"app/compose.yaml",
+				"app/deploy.yaml",
+				"chart/values.yaml",
+				"k/overlays/prod/kustomization.yaml",
+				"vendor/Dockerfile",
+			},
+		},
+		{
+			name: "exclude",
+			opts: WalkOptions{Exclude: []string{"vendor", "app/*.yaml"}},
+			want: []string{
+				"Dockerfile",
+				"chart/values.yaml",
+				"k/overlays/prod/kustomization.yaml",
+			},
+		},
+		{
+			name: "include",
+			opts: WalkOptions{Include: []string{"**/Dockerfile"}},
+			want: []string{"Dockerfile", "vendor/Dockerfile"},
+		},
+	}
+	s := NewSet(Options{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Walk(root, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range got {
+				rel, _ := filepath.Rel(root, got[i])
+				got[i] = filepath.ToSlash(rel)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Walk() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package scanner
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// readYAML parses every document of a YAML file
+func readYAML(path string) ([]*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseYAML(data)
+}
+
+// parseYAML parses every document of a YAML stream
+func parseYAML(data []byte) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				return docs, nil
+			}
+			return nil, err
+		}
+		if root := documentRoot(&doc); root != nil {
+			docs = append(docs, root)
+		}
+	}
+}
+
+// documentRoot returns the top-level node of a document, or nil when empty
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == yaml.DocumentNode {
+		if len(doc.Content) == 0 {
+			return nil
+		}
+		return resolve(doc.Content[0])
+	}
+	return resolve(doc)
+}
+
+// resolve follows aliases to the node they point to
+func resolve(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// yamlEntry is a key/value pair of a mapping node
+type yamlEntry struct {
+	key   *yaml.Node
+	value *yaml.Node
+}
+
+// mappingEntries returns the entries of a mapping in order, with "<<" merge
+// keys expanded. Explicit keys win over merged ones.
+func mappingEntries(n *yaml.Node) []yamlEntry {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var merged, own []yamlEntry
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], resolve(n.Content[i+1])
+		if key.Value == "<<" && key.Tag == "!!merge" {
+			sources := []*yaml.Node{value}
+			if value.Kind == yaml.SequenceNode {
+				sources = value.Content
+			}
+			for _, src := range sources {
+				merged = append(merged, mappingEntries(src)...)
+			}
+			continue
+		}
+		own = append(own, yamlEntry{key: key, value: value})
+	}
+	if len(merged) == 0 {
+		return own
+	}
+
+	seen := make(map[string]bool, len(own))
+	for _, e := range own {
+		seen[e.key.Value] = true
+	}
+	entries := own
+	for _, e := range merged {
+		if !seen[e.key.Value] {
+			seen[e.key.Value] = true
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// mappingValue returns the value of key in a mapping, or nil
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	for _, e := range mappingEntries(n) {
+		if e.key.Value == key {
+			return e.value
+		}
+	}
+	return nil
+}
+
+// scalarValue returns the value of a scalar node
+func scalarValue(n *yaml.Node) (string, bool) {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.ScalarNode || n.Tag == "!!null" {
+		return "", false
+	}
+	return n.Value, true
+}
+
+// mappingString returns the scalar value of key in a mapping with its node
+func mappingString(n *yaml.Node, key string) (string, *yaml.Node) {
+	value := mappingValue(n, key)
+	s, ok := scalarValue(value)
+	if !ok {
+		return "", nil
+	}
+	return s, value
+}
+
+// sequenceItems returns the items of a sequence node
+func sequenceItems(n *yaml.Node) []*yaml.Node {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return nil
+	}
+	items := make([]*yaml.Node, len(n.Content))
+	for i, item := range n.Content {
+		items[i] = resolve(item)
+	}
+	return items
+}
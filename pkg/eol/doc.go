@@ -0,0 +1,11 @@
+// Package eol is the public Go API of the EOL checker. It lets other programs
+// look up product lifecycles on endoflife.date and evaluate container images
+// in-process, with the same results as the eol command.
+//
+// Compatibility: the exported identifiers of this package follow semantic
+// versioning. Within a major version, exported functions, types, options and
+// the JSON field names of Result and Cycle are neither removed nor changed in
+// an incompatible way. New options, fields and status values may be added in
+// minor releases, so callers should tolerate unknown Status strings.
+// Everything under internal/ is exempt and may change at any time.
+package eol
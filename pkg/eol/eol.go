@@ -0,0 +1,171 @@
+package eol
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/HMZElidrissi/eol-checker/internal/api"
+	"github.com/HMZElidrissi/eol-checker/internal/evaluator"
+	"github.com/HMZElidrissi/eol-checker/internal/models"
+	"github.com/HMZElidrissi/eol-checker/internal/version"
+	"github.com/HMZElidrissi/eol-checker/pkg/image"
+)
+
+// DefaultBaseURL is the endoflife.date API used when no base URL is set
+const DefaultBaseURL = "https://endoflife.date/api"
+
+// DefaultThresholds returns the thresholds used when none are configured
+func DefaultThresholds() Thresholds {
+	return Thresholds{WarningDays: 30, InfoDays: 90}
+}
+
+type options struct {
+	httpClient *http.Client
+	baseURL    string
+	now        func() time.Time
+	thresholds *Thresholds
+}
+
+// Option configures a Client or an Evaluator
+type Option func(*options)
+
+// WithHTTPClient sets the HTTP client used for API requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithBaseURL sets the endoflife.date API base URL, e.g. for a mirror
+func WithBaseURL(baseURL string) Option {
+	return func(o *options) {
+		o.baseURL = baseURL
+	}
+}
+
+// WithClock sets the function used to get the current time
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// WithThresholds sets the WARNING and INFO thresholds
+func WithThresholds(thresholds Thresholds) Option {
+	return func(o *options) {
+		o.thresholds = &thresholds
+	}
+}
+
+func buildOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (o options) clientOptions() []api.ClientOption {
+	var clientOpts []api.ClientOption
+	if o.httpClient != nil {
+		clientOpts = append(clientOpts, api.WithHTTPClient(o.httpClient))
+	}
+	if o.baseURL != "" {
+		clientOpts = append(clientOpts, api.WithBaseURL(o.baseURL))
+	}
+	return clientOpts
+}
+
+func (o options) evaluatorOptions() []evaluator.Option {
+	var evalOpts []evaluator.Option
+	if o.now != nil {
+		evalOpts = append(evalOpts, evaluator.WithClock(o.now))
+	}
+	if o.thresholds != nil {
+		evalOpts = append(evalOpts, evaluator.WithThresholds(o.thresholds.model()))
+	}
+	return evalOpts
+}
+
+// Client fetches product lifecycles from endoflife.date
+type Client struct {
+	client *api.Client
+}
+
+// NewClient creates a client. Only WithHTTPClient and WithBaseURL apply.
+func NewClient(opts ...Option) *Client {
+	o := buildOptions(opts)
+	return &Client{client: api.NewClient(o.clientOptions()...)}
+}
+
+// GetProductCycles fetches the release cycles of a product. It returns nil
+// and no error when the product is unknown to endoflife.date.
+func (c *Client) GetProductCycles(product string) ([]Cycle, error) {
+	cycles, err := c.client.GetProductCycles(product)
+	if err != nil || cycles == nil {
+		return nil, err
+	}
+	result := make([]Cycle, len(cycles))
+	for i, cycle := range cycles {
+		result[i] = newCycle(cycle)
+	}
+	return result, nil
+}
+
+// Matcher finds the release cycle that matches a version
+type Matcher struct {
+	matcher *version.Matcher
+}
+
+// NewMatcher creates a version matcher
+func NewMatcher() *Matcher {
+	return &Matcher{matcher: version.NewMatcher()}
+}
+
+// FindBestMatch returns the cycle that best matches version, or nil
+func (m *Matcher) FindBestMatch(version string, cycles []Cycle) *Cycle {
+	converted := make([]models.EOLCycle, len(cycles))
+	for i, cycle := range cycles {
+		converted[i] = cycle.model()
+	}
+	match := m.matcher.FindBestMatch(version, converted)
+	for i := range converted {
+		if match == &converted[i] {
+			return &cycles[i]
+		}
+	}
+	return nil
+}
+
+// Evaluator determines the EOL status of container images. It is safe for
+// concurrent use.
+type Evaluator struct {
+	evaluator *evaluator.Evaluator
+}
+
+// NewEvaluator creates an evaluator backed by the endoflife.date API
+func NewEvaluator(opts ...Option) *Evaluator {
+	o := buildOptions(opts)
+	return &Evaluator{
+		evaluator: evaluator.New(
+			api.NewClient(o.clientOptions()...),
+			version.NewMatcher(),
+			image.NewParser(),
+			o.evaluatorOptions()...,
+		),
+	}
+}
+
+// Evaluate checks the EOL status of an image reference such as "nginx:1.20"
+func (e *Evaluator) Evaluate(imageName string) (Result, error) {
+	result, err := e.evaluator.Evaluate(imageName)
+	if err != nil {
+		return Result{}, err
+	}
+	return newResult(result), nil
+}
+
+// Evaluate checks the EOL status of a single image reference
+func Evaluate(imageName string, opts ...Option) (Result, error) {
+	return NewEvaluator(opts...).Evaluate(imageName)
+}
@@ -0,0 +1,100 @@
+package eol
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const nginxCycles = `[
+	{"cycle": "1.27", "releaseDate": "2024-05-29", "eol": false, "latest": "1.27.2", "link": null, "lts": false},
+	{"cycle": "1.20", "releaseDate": "2021-05-25", "eol": "2022-05-24", "latest": "1.20.2", "link": "https://nginx.org/en/CHANGES-1.20", "lts": false, "support": true}
+]`
+
+func server(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/nginx.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(nginxCycles))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestClientGetProductCycles(t *testing.T) {
+	client := NewClient(WithBaseURL(server(t).URL))
+	cycles, err := client.GetProductCycles("nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Cycle{
+		{Cycle: "1.27", ReleaseDate: "2024-05-29", Latest: "1.27.2"},
+		{
+			Cycle:       "1.20",
+			ReleaseDate: "2021-05-25",
+			EOL:         Milestone{Date: "2022-05-24"},
+			Latest:      "1.20.2",
+			Link:        "https://nginx.org/en/CHANGES-1.20",
+			Support:     Milestone{Reached: true},
+		},
+	}
+	if !reflect.DeepEqual(cycles, want) {
+		t.Errorf("GetProductCycles() = %+v, want %+v", cycles, want)
+	}
+
+	if match := NewMatcher().FindBestMatch("1.20.1", cycles); match != &cycles[1] {
+		t.Errorf("FindBestMatch() = %v, want the 1.20 cycle", match)
+	}
+}
+
+func TestEvaluatorEvaluate(t *testing.T) {
+	ev := NewEvaluator(
+		WithBaseURL(server(t).URL),
+		WithClock(func() time.Time { return time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC) }),
+	)
+	result, err := ev.Evaluate("nginx:1.20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != StatusCritical || result.EOLDate != "2022-05-24" || result.Product != "nginx" {
+		t.Errorf("Evaluate() = %+v, want a CRITICAL nginx result with EOL date 2022-05-24", result)
+	}
+}
+
+func TestMilestoneJSON(t *testing.T) {
+	tests := []struct {
+		json string
+		want Milestone
+	}{
+		{`"2025-04-30"`, Milestone{Date: "2025-04-30"}},
+		{`true`, Milestone{Reached: true}},
+		{`false`, Milestone{}},
+	}
+	for _, tt := range tests {
+		var m Milestone
+		if err := json.Unmarshal([]byte(tt.json), &m); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", tt.json, err)
+		}
+		if m != tt.want {
+			t.Errorf("Unmarshal(%s) = %+v, want %+v", tt.json, m, tt.want)
+		}
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != tt.json {
+			t.Errorf("Marshal(%+v) = %s, want %s", m, data, tt.json)
+		}
+	}
+
+	var m Milestone
+	if err := json.Unmarshal([]byte(`12`), &m); err == nil {
+		t.Error("Unmarshal(12) succeeded, want an error")
+	}
+}
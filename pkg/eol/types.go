@@ -0,0 +1,168 @@
+package eol
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/HMZElidrissi/eol-checker/internal/evaluator"
+	"github.com/HMZElidrissi/eol-checker/internal/models"
+)
+
+// Result statuses, from most to least severe
+const (
+	StatusCritical = "CRITICAL"
+	StatusWarning  = "WARNING"
+	StatusInfo     = "INFO"
+	StatusOK       = "OK"
+	StatusUnknown  = "UNKNOWN"
+)
+
+// Result is the EOL analysis of a container image
+type Result struct {
+	Product        string `json:"product"`
+	Version        string `json:"version"`
+	Status         string `json:"status"`
+	Description    string `json:"description"`
+	Recommendation string `json:"recommendation"`
+	Link           string `json:"link"`
+	// EOLDate and SupportEndDate are YYYY-MM-DD dates, empty when the
+	// release has none
+	EOLDate        string `json:"eolDate"`
+	SupportEndDate string `json:"supportEndDate"`
+	// DaysRemaining is the number of days until the EOL date, -1 when
+	// there is none
+	DaysRemaining int    `json:"daysRemaining"`
+	Latest        string `json:"latest"`
+}
+
+// Cycle is a product release cycle as returned by endoflife.date
+type Cycle struct {
+	Cycle        string    `json:"cycle"`
+	ReleaseDate  string    `json:"releaseDate"`
+	EOL          Milestone `json:"eol"`
+	Latest       string    `json:"latest"`
+	Link         string    `json:"link,omitempty"`
+	LTS          Milestone `json:"lts"`
+	Support      Milestone `json:"support"`
+	Discontinued Milestone `json:"discontinued"`
+}
+
+// Milestone is a point in the lifecycle of a cycle, such as its end of life.
+// endoflife.date gives either its date or only whether it has been reached,
+// and Milestone is encoded in JSON the same way.
+type Milestone struct {
+	// Date is the YYYY-MM-DD date of the milestone, when known
+	Date string
+	// Reached tells whether the milestone has passed when Date is empty
+	Reached bool
+}
+
+// MarshalJSON encodes the milestone as its date, or as whether it is reached
+func (m Milestone) MarshalJSON() ([]byte, error) {
+	if m.Date != "" {
+		return json.Marshal(m.Date)
+	}
+	return json.Marshal(m.Reached)
+}
+
+// UnmarshalJSON decodes a date, a boolean or null
+func (m *Milestone) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	switch v := value.(type) {
+	case nil:
+		*m = Milestone{}
+	case string:
+		*m = Milestone{Date: v}
+	case bool:
+		*m = Milestone{Reached: v}
+	default:
+		return fmt.Errorf("invalid milestone %s", data)
+	}
+	return nil
+}
+
+// Thresholds holds the number of days before EOL or end of support at which
+// a result is reported as WARNING or INFO
+type Thresholds struct {
+	WarningDays int `json:"warningDays"`
+	InfoDays    int `json:"infoDays"`
+}
+
+// newResult converts a result of the evaluator
+func newResult(r models.EOLResult) Result {
+	return Result{
+		Product:        r.Product,
+		Version:        r.Version,
+		Status:         r.Status,
+		Description:    r.Description,
+		Recommendation: r.Recommendation,
+		Link:           r.Link,
+		EOLDate:        r.EOLDate,
+		SupportEndDate: r.SupportEndDate,
+		DaysRemaining:  r.DaysRemaining,
+		Latest:         r.Latest,
+	}
+}
+
+// newCycle converts a cycle of the API client
+func newCycle(c models.EOLCycle) Cycle {
+	cycle := Cycle{
+		Cycle:        string(c.Cycle),
+		ReleaseDate:  c.ReleaseDate,
+		EOL:          newMilestone(c.EOL),
+		Latest:       c.Latest,
+		LTS:          newMilestone(c.LTS),
+		Support:      newMilestone(c.Support),
+		Discontinued: newMilestone(c.Discontinued),
+	}
+	if c.Link != nil {
+		cycle.Link = *c.Link
+	}
+	return cycle
+}
+
+// model converts the cycle back for the version matcher
+func (c Cycle) model() models.EOLCycle {
+	cycle := models.EOLCycle{
+		Cycle:        models.CycleString(c.Cycle),
+		ReleaseDate:  c.ReleaseDate,
+		EOL:          c.EOL.value(),
+		Latest:       c.Latest,
+		LTS:          c.LTS.value(),
+		Support:      c.Support.value(),
+		Discontinued: c.Discontinued.value(),
+	}
+	if c.Link != "" {
+		link := c.Link
+		cycle.Link = &link
+	}
+	return cycle
+}
+
+// newMilestone converts a date or boolean of the API
+func newMilestone(value interface{}) Milestone {
+	switch v := value.(type) {
+	case string:
+		return Milestone{Date: v}
+	case bool:
+		return Milestone{Reached: v}
+	default:
+		return Milestone{}
+	}
+}
+
+// value returns the milestone as the API gives it
+func (m Milestone) value() interface{} {
+	if m.Date != "" {
+		return m.Date
+	}
+	return m.Reached
+}
+
+// model converts the thresholds for the evaluator
+func (t Thresholds) model() evaluator.Thresholds {
+	return evaluator.Thresholds{WarningDays: t.WarningDays, InfoDays: t.InfoDays}
+}
@@ -8,11 +8,11 @@ import (
 
 // ImageInfo represents parsed container image information
 type ImageInfo struct {
-	Registry string
-	Name     string
-	Tag      string
-	Product  string
-	Version  string
+	Registry string `json:"registry,omitempty"`
+	Name     string `json:"name"`
+	Tag      string `json:"tag"`
+	Product  string `json:"product"`
+	Version  string `json:"version"`
 }
 
 // Parser handles container image name parsing
@@ -60,6 +60,11 @@ func (p *Parser) Parse(imageName string) (*ImageInfo, error) {
 		version = ""
 	}
 
+	// Strip the leading v of tags such as v1.27.3
+	if len(version) > 1 && version[0] == 'v' && version[1] >= '0' && version[1] <= '9' {
+		version = version[1:]
+	}
+
 	return &ImageInfo{
 		Registry: registry,
 		Name:     name,